@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gunererd/grease/internal/filemanager"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// operationJSON is how an operation and its outcome are printed with -json
+type operationJSON struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Target string `json:"target,omitempty"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// runApply makes a directory match the listing read from stdin without
+// starting the UI, e.g. `grease apply -dry-run dir < listing.txt`. It
+// returns the exit code: 1 when an operation failed, 2 for bad usage.
+func runApply(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: grease apply [flags] <dir> < listing.txt")
+		flags.PrintDefaults()
+	}
+	dryRun := flags.Bool("dry-run", false, "print the operations without applying them")
+	asJSON := flags.Bool("json", false, "print operations and results as JSON")
+	onFailure := flags.String("on-failure", "stop", "what to do after an operation fails: stop or skip")
+	onConflict := flags.String("on-conflict", "ask", "what to do when a target already exists: overwrite, skip or suffix; ask fails the operation")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	policy, err := types.ParseFailurePolicy(*onFailure)
+	if err == nil && policy == types.AskOnFailure {
+		err = fmt.Errorf("-on-failure ask needs the interactive UI")
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	resolution, err := types.ParseConflictResolution(*onConflict)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	opts := []filemanager.Option{
+		// A log file in the working directory could end up in the listing
+		filemanager.WithLog(os.DevNull),
+		filemanager.WithFailurePolicy(policy),
+		filemanager.WithConflictResolution(resolution),
+	}
+	dir := flags.Arg(0)
+
+	if *dryRun {
+		ops, err := filemanager.Plan(dir, stdin, opts...)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+
+		results := make([]types.OperationResult, len(ops))
+		for i, op := range ops {
+			results[i] = types.OperationResult{Operation: op}
+		}
+		return printResults(stdout, stderr, results, *asJSON, false)
+	}
+
+	results, err := filemanager.Apply(dir, stdin, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return printResults(stdout, stderr, results, *asJSON, true)
+}
+
+// printResults writes one line per operation to w, with its status once it
+// was executed, and returns 1 if any of them failed
+func printResults(w, stderr io.Writer, results []types.OperationResult, asJSON, executed bool) int {
+	code := 0
+	records := make([]operationJSON, 0, len(results))
+	for _, result := range results {
+		if result.Status == types.OperationFailed {
+			code = 1
+		}
+
+		record := operationJSON{
+			Type:   result.Operation.Type().String(),
+			Source: result.Operation.Source(),
+			Target: result.Operation.Target(),
+		}
+		if executed {
+			record.Status = result.Status.String()
+		}
+		if result.Err != nil {
+			record.Error = result.Err.Error()
+		}
+		records = append(records, record)
+
+		if asJSON {
+			continue
+		}
+		line := operation.Describe(result.Operation)
+		if executed {
+			line = fmt.Sprintf("%-8s %s", result.Status, line)
+		}
+		if result.Err != nil {
+			line += ": " + result.Err.Error()
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gunererd/grease/internal/filemanager"
+	"github.com/gunererd/grease/internal/filemanager/compare"
+)
+
+// differenceJSON is how a difference is printed with -json
+type differenceJSON struct {
+	Status string `json:"status"`
+	Path   string `json:"path"`
+}
+
+// runDiff prints how two directory trees differ without starting the UI,
+// e.g. `grease diff backup/ photos/`. Like diff(1) it returns 0 when they
+// are the same, 1 when they differ and 2 on errors.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: grease diff [flags] <left> <right>")
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print the differences as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	diffs, err := filemanager.Compare(flags.Arg(0), flags.Arg(1), filemanager.WithLog(os.DevNull))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	if *asJSON {
+		records := make([]differenceJSON, len(diffs))
+		for i, d := range diffs {
+			records[i] = differenceJSON{Status: d.Status.String(), Path: d.Path}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, d := range diffs {
+			fmt.Fprintln(stdout, compare.Format(d))
+		}
+	}
+
+	if len(diffs) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -25,6 +25,10 @@ func (c *PasteCommand) Execute(lines []string, pos types.Position, register *reg
 	}
 
 	textLines := strings.Split(text, "\n")
+	if register.Linewise() {
+		return insertLines(lines, pos, textLines, c.before)
+	}
+
 	insertPos := pos.Column()
 	if !c.before {
 		insertPos++
@@ -40,6 +44,24 @@ func (c *PasteCommand) Name() string {
 	return "paste"
 }
 
+// insertLines puts whole lines below the cursor line, or above it when
+// before is set, and moves the cursor to the first of them
+func insertLines(lines []string, pos types.Position, textLines []string, before bool) ([]string, types.Position) {
+	at := pos.Line() + 1
+	if before {
+		at = pos.Line()
+	}
+	if at > len(lines) {
+		at = len(lines)
+	}
+
+	newLines := make([]string, 0, len(lines)+len(textLines))
+	newLines = append(newLines, lines[:at]...)
+	newLines = append(newLines, textLines...)
+	newLines = append(newLines, lines[at:]...)
+	return newLines, buffer.NewPosition(at, 0)
+}
+
 func insertSingleLine(lines []string, pos types.Position, text string, insertPos int) ([]string, types.Position) {
 	// Handle position beyond buffer
 	if pos.Line() >= len(lines) {
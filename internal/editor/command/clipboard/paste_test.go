@@ -26,6 +26,7 @@ func (s *PasteTestSuite) TestPasteCommand() {
 		pasteText     string
 		pos           types.Position
 		before        bool
+		linewise      bool
 		expectedLines []string
 		expectedPos   types.Position
 	}{
@@ -65,12 +66,44 @@ func (s *PasteTestSuite) TestPasteCommand() {
 			expectedLines: []string{"test", ""},
 			expectedPos:   buffer.NewPosition(0, 3),
 		},
+		{
+			name:          "paste line below",
+			input:         "hello\nworld",
+			pasteText:     "test",
+			pos:           buffer.NewPosition(0, 2),
+			linewise:      true,
+			expectedLines: []string{"hello", "test", "world"},
+			expectedPos:   buffer.NewPosition(1, 0),
+		},
+		{
+			name:          "paste line above",
+			input:         "hello\nworld",
+			pasteText:     "test",
+			pos:           buffer.NewPosition(0, 2),
+			before:        true,
+			linewise:      true,
+			expectedLines: []string{"test", "hello", "world"},
+			expectedPos:   buffer.NewPosition(0, 0),
+		},
+		{
+			name:          "paste line below last line",
+			input:         "hello",
+			pasteText:     "test",
+			pos:           buffer.NewPosition(0, 0),
+			linewise:      true,
+			expectedLines: []string{"hello", "test"},
+			expectedPos:   buffer.NewPosition(1, 0),
+		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
 			lines := strings.Split(tt.input, "\n")
-			s.register.Set(tt.pasteText)
+			if tt.linewise {
+				s.register.SetLines(tt.pasteText)
+			} else {
+				s.register.Set(tt.pasteText)
+			}
 			cmd := NewPasteCommand(tt.before)
 
 			resultLines, resultPos := cmd.Execute(lines, tt.pos, s.register)
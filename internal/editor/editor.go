@@ -25,10 +25,15 @@ type Editor struct {
 	showLineNumbers  bool
 	statusLine       types.StatusLine
 	handlers         map[state.Mode]types.ModeHandler
+	commandMode      *handler.CommandMode
 	highlightManager types.HighlightManager
 	historyManager   types.HistoryManager
 	executor         *handler.CommandExecutor
 	hookManager      types.HookManager
+	message          string
+	messageIsError   bool
+	selection        [2]int
+	hasSelection     bool
 	logger           types.Logger
 }
 
@@ -45,6 +50,7 @@ func New(
 	hookManager types.HookManager,
 	logger types.Logger,
 ) *Editor {
+	commandMode := handler.NewCommandMode(kt, register, hlm, executor, logger)
 	e := &Editor{
 		buffer:          b,
 		viewport:        wp, // Default size
@@ -56,8 +62,9 @@ func New(
 			state.NormalMode:  handler.NewNormalMode(kt, register, hm, executor, logger),
 			state.InsertMode:  handler.NewInsertMode(),
 			state.VisualMode:  handler.NewVisualMode(kt, register, hlm, logger),
-			state.CommandMode: handler.NewCommandMode(kt, register, hlm, logger),
+			state.CommandMode: commandMode,
 		},
+		commandMode:      commandMode,
 		highlightManager: hlm,
 		historyManager:   hm,
 		executor:         executor,
@@ -83,6 +90,16 @@ func (e *Editor) GetHooks() []types.Hook {
 	return e.hookManager.GetHooks()
 }
 
+// RegisterCommand makes a command available by name in command mode
+func (e *Editor) RegisterCommand(name string, factory types.CommandFactory) {
+	e.commandMode.Register(name, factory)
+}
+
+// RegisterCompletion sets how the arguments of a command are completed
+func (e *Editor) RegisterCompletion(name string, completer types.Completer) {
+	e.commandMode.RegisterCompletion(name, completer)
+}
+
 func (e *Editor) IO() types.IOManager {
 	return e.io
 }
@@ -122,6 +139,7 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		e.UpdateViewport(msg.Width, msg.Height)
 	case tea.KeyMsg:
+		e.message = ""
 		return e.handleKeyPress(msg)
 	}
 	return e, nil
@@ -139,6 +157,13 @@ func (e *Editor) View() string {
 }
 
 func (e *Editor) getStatusLine() string {
+	if e.mode == state.CommandMode {
+		return ":" + e.commandMode.GetBuffer()
+	}
+	if e.message != "" {
+		return e.statusLine.RenderMessage(e.message, e.messageIsError, e.Width())
+	}
+
 	cursor, _ := e.Buffer().GetPrimaryCursor()
 	mode := e.getModeString()
 	x, y := e.Viewport().BufferToViewportPosition(cursor.GetPosition())
@@ -173,7 +198,31 @@ func (e *Editor) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return e, nil
 }
 
+func (e *Editor) SetMessage(text string, isError bool) {
+	e.message = text
+	e.messageIsError = isError
+}
+
+func (e *Editor) Mode() state.Mode {
+	return e.mode
+}
+
+func (e *Editor) SetSelection(start, end int) {
+	if end < start {
+		start, end = end, start
+	}
+	e.selection = [2]int{start, end}
+	e.hasSelection = true
+}
+
+func (e *Editor) Selection() (start, end int, ok bool) {
+	return e.selection[0], e.selection[1], e.hasSelection
+}
+
 func (e *Editor) SetMode(mode state.Mode) {
+	if e.mode == state.CommandMode && mode != state.CommandMode {
+		e.hasSelection = false
+	}
 	e.mode = mode
 	e.Viewport().SetMode(mode)
 }
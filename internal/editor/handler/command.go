@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"slices"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gunererd/grease/internal/editor/keytree"
@@ -11,25 +15,45 @@ import (
 )
 
 type CommandMode struct {
-	buffer   string
-	executor *CommandExecutor
-	logger   types.Logger
+	buffer     string
+	executor   *CommandExecutor
+	commands   map[string]types.CommandFactory
+	completers map[string]types.Completer
+	completion *completion // set while tab cycles through candidates
+	logger     types.Logger
 }
 
+// completion is what tab cycles through once the candidates have nothing
+// more in common
+type completion struct {
+	head       string // text before the completed word
+	candidates []string
+	index      int
+}
+
+// builtinCommands are the names parseCommand knows without registration
+var builtinCommands = []string{"w", "write"}
+
 func NewCommandMode(
 	kt *keytree.KeyTree,
 	register *register.Register,
 	hlm types.HighlightManager,
+	executor *CommandExecutor,
 	logger types.Logger,
 ) *CommandMode {
 	return &CommandMode{
-		buffer: "",
-		// executor: executor,
-		logger: logger,
+		buffer:     "",
+		executor:   executor,
+		commands:   make(map[string]types.CommandFactory),
+		completers: make(map[string]types.Completer),
+		logger:     logger,
 	}
 }
 
 func (h *CommandMode) Handle(msg tea.KeyMsg, e types.Editor) (types.Editor, tea.Cmd) {
+	if key := msg.String(); key != "tab" && key != "shift+tab" {
+		h.completion = nil
+	}
 
 	switch msg.String() {
 	case "esc", "ctrl+c":
@@ -38,11 +62,19 @@ func (h *CommandMode) Handle(msg tea.KeyMsg, e types.Editor) (types.Editor, tea.
 	case "enter":
 		e = h.executeCommand(e)
 		h.buffer = ""
-		e.SetMode(state.NormalMode)
+		if e.Mode() == state.CommandMode {
+			e.SetMode(state.NormalMode)
+		}
 	case "backspace":
 		if len(h.buffer) > 0 {
 			h.buffer = h.buffer[:len(h.buffer)-1]
 		}
+	case " ":
+		h.buffer += " "
+	case "tab":
+		h.complete(1)
+	case "shift+tab":
+		h.complete(-1)
 	default:
 		h.buffer += string(msg.Runes)
 	}
@@ -54,7 +86,7 @@ func (h *CommandMode) Handle(msg tea.KeyMsg, e types.Editor) (types.Editor, tea.
 
 func (h *CommandMode) executeCommand(e types.Editor) types.Editor {
 	// Parse command from buffer
-	cmd := parseCommand(h.buffer)
+	cmd := h.parseCommand(h.buffer)
 	if cmd == nil {
 		h.logger.Printf("Invalid command: %s", h.buffer)
 		return e
@@ -67,15 +99,140 @@ func (h *CommandMode) GetBuffer() string {
 	return h.buffer
 }
 
-func parseCommand(input string) types.Command {
-	parts := strings.Fields(input)
-	if len(parts) == 0 {
+// SetBuffer replaces the text typed so far, e.g. after completion.
+func (h *CommandMode) SetBuffer(buffer string) {
+	h.buffer = buffer
+}
+
+// Register adds a command that can be invoked by name from command mode.
+// Registered commands take precedence over the built-in ones.
+func (h *CommandMode) Register(name string, factory types.CommandFactory) {
+	h.commands[name] = factory
+}
+
+// RegisterCompletion sets how the arguments of the command called name are
+// completed
+func (h *CommandMode) RegisterCompletion(name string, completer types.Completer) {
+	h.completers[name] = completer
+}
+
+// complete extends the word being typed as far as its candidates agree.
+// When they agree no further, each press moves on to the next candidate,
+// or the previous one for a negative step.
+func (h *CommandMode) complete(step int) {
+	if h.completion == nil {
+		head, word, candidates := h.candidates()
+		if len(candidates) == 0 {
+			return
+		}
+		if prefix := commonPrefix(candidates); len(candidates) == 1 || len(prefix) > len(word) {
+			h.buffer = head + prefix
+			return
+		}
+		h.completion = &completion{head: head, candidates: candidates, index: -1}
+	}
+
+	c := h.completion
+	if c.index < 0 && step < 0 {
+		c.index = len(c.candidates)
+	}
+	c.index = (c.index + step + len(c.candidates)) % len(c.candidates)
+	h.buffer = c.head + c.candidates[c.index]
+}
+
+// candidates returns what the word being typed can be completed to along
+// with the text before it. Until a space is typed the word is the name of
+// a command, afterwards the arguments as a whole.
+func (h *CommandMode) candidates() (head, word string, candidates []string) {
+	name, _ := splitCommand(h.buffer)
+	typed := strings.TrimLeft(h.buffer, " ")
+
+	if name == "" || typed == name {
+		for _, candidate := range h.commandNames() {
+			if strings.HasPrefix(candidate, typed) {
+				candidates = append(candidates, candidate+" ")
+			}
+		}
+		return h.buffer[:len(h.buffer)-len(typed)], typed, candidates
+	}
+
+	completer, ok := h.completers[name]
+	if !ok || !strings.HasPrefix(typed, name) {
+		return "", "", nil
+	}
+	word = strings.TrimLeft(typed[len(name):], " ")
+	return h.buffer[:len(h.buffer)-len(word)], word, completer(word)
+}
+
+// commandNames returns the names of all commands in order
+func (h *CommandMode) commandNames() []string {
+	names := append([]string{}, builtinCommands...)
+	for name := range h.commands {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commonPrefix returns the longest prefix all of words share, not cutting
+// a character in half
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		i := 0
+		for i < len(prefix) && i < len(word) && prefix[i] == word[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+func (h *CommandMode) parseCommand(input string) types.Command {
+	name, args := splitCommand(input)
+	if name == "" {
 		return nil
 	}
 
-	switch parts[0] {
+	if factory, ok := h.commands[name]; ok {
+		return factory(args)
+	}
+
+	switch name {
 	case "w", "write":
 		return CreateWriteCommand()
 	}
 	return nil
 }
+
+// splitCommand separates the command name from its arguments. Names are a
+// run of letters and dashes optionally prefixed with a range such as "%",
+// so "%s/a/b/" splits into "%s" and "/a/b/". A leading "!" is a name on its
+// own.
+func splitCommand(input string) (name, args string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", ""
+	}
+	if input[0] == '!' {
+		return "!", strings.TrimSpace(input[1:])
+	}
+
+	i := 0
+	if input[0] == '%' {
+		i++
+	}
+	for i < len(input) && (unicode.IsLetter(rune(input[i])) || input[i] == '-') {
+		i++
+	}
+	if i == 0 {
+		return "", ""
+	}
+
+	return input[:i], strings.TrimSpace(input[i:])
+}
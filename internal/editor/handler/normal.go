@@ -59,6 +59,10 @@ func NewNormalMode(
 				nm.logger.Println("Failed to get primary cursor:", err)
 				return e
 			}
+			// Like vim, the deleted line can be put again with p
+			if line, err := e.Buffer().GetLine(cursor.GetPosition().Line()); err == nil {
+				nm.register.SetLines(line)
+			}
 			cmd := CreateDeleteLineCommand(cursor)
 			return nm.executor.Execute(cmd, e)
 		},
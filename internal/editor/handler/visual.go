@@ -94,6 +94,12 @@ func (vm *VisualMode) Handle(msg tea.KeyMsg, e types.Editor) (types.Editor, tea.
 	case "i":
 		vm.cleanup(e)
 		e.SetMode(state.InsertMode)
+	case ":":
+		// The command typed next applies to the selected lines
+		vm.cleanup(e)
+		e.SetSelection(vm.selectionStart.Line(), cursor.GetPosition().Line())
+		e.SetMode(state.CommandMode)
+		return e, nil
 	case "q":
 		return e, tea.Quit
 	case "$":
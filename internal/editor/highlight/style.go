@@ -1,6 +1,8 @@
 package highlight
 
 import (
+	"sync"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gunererd/grease/internal/editor/types"
 )
@@ -9,6 +11,14 @@ import (
 type Style struct {
 	Visual lipgloss.Style
 	Search lipgloss.Style
+
+	GitModified  lipgloss.Style
+	GitStaged    lipgloss.Style
+	GitUntracked lipgloss.Style
+	GitIgnored   lipgloss.Style
+	GitConflict  lipgloss.Style
+
+	Marked lipgloss.Style
 }
 
 // NewStyle creates a new Style with default values
@@ -21,6 +31,19 @@ func NewStyle() *Style {
 		Search: lipgloss.NewStyle().
 			Background(lipgloss.Color("#755800")).
 			Foreground(lipgloss.Color("#ffffff")),
+
+		GitModified:  lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b")),
+		GitStaged:    lipgloss.NewStyle().Foreground(lipgloss.Color("#98c379")),
+		GitUntracked: lipgloss.NewStyle().Foreground(lipgloss.Color("#61afef")),
+		GitIgnored:   lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370")),
+		GitConflict: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#e06c75")).
+			Bold(true),
+
+		Marked: lipgloss.NewStyle().
+			Background(lipgloss.Color("#2f4a3a")).
+			Foreground(lipgloss.Color("#ffffff")).
+			Bold(true),
 	}
 }
 
@@ -31,7 +54,46 @@ func (s *Style) GetStyle(t types.HighlightType) lipgloss.Style {
 		return s.Visual
 	case types.SearchHighlight:
 		return s.Search
+	case types.GitModifiedHighlight:
+		return s.GitModified
+	case types.GitStagedHighlight:
+		return s.GitStaged
+	case types.GitUntrackedHighlight:
+		return s.GitUntracked
+	case types.GitIgnoredHighlight:
+		return s.GitIgnored
+	case types.GitConflictHighlight:
+		return s.GitConflict
+	case types.MarkedHighlight:
+		return s.Marked
 	default:
+		return customStyle(t)
+	}
+}
+
+// custom holds the styles of highlight types defined at runtime
+var custom = struct {
+	sync.RWMutex
+	styles []lipgloss.Style
+}{}
+
+// DefineStyle returns a new highlight type drawn with style, for colours
+// that are only known at runtime such as those from LS_COLORS
+func DefineStyle(style lipgloss.Style) types.HighlightType {
+	custom.Lock()
+	defer custom.Unlock()
+
+	custom.styles = append(custom.styles, style)
+	return types.CustomHighlight + types.HighlightType(len(custom.styles)-1)
+}
+
+func customStyle(t types.HighlightType) lipgloss.Style {
+	custom.RLock()
+	defer custom.RUnlock()
+
+	i := int(t - types.CustomHighlight)
+	if i < 0 || i >= len(custom.styles) {
 		return lipgloss.NewStyle()
 	}
+	return custom.styles[i]
 }
@@ -112,6 +112,24 @@ func CreateSearchHighlight(start, end types.Position) types.Highlight {
 	return NewHighlight(start, end, types.SearchHighlight, 50)
 }
 
+// CreateDecorationHighlight creates a highlight that marks up text, such as
+// the git status of an entry. It sits below selections and search results.
+func CreateDecorationHighlight(start, end types.Position, highlightType types.HighlightType) types.Highlight {
+	return NewHighlight(start, end, highlightType, 10)
+}
+
+// CreateMarkedHighlight creates a highlight for an entry marked to be picked.
+// It sits above decorations so the mark shows whatever else the entry has.
+func CreateMarkedHighlight(start, end types.Position) types.Highlight {
+	return NewHighlight(start, end, types.MarkedHighlight, 20)
+}
+
+// CreateColorHighlight creates a highlight that colours text, such as an
+// entry by its file type. It sits below every other highlight.
+func CreateColorHighlight(start, end types.Position, highlightType types.HighlightType) types.Highlight {
+	return NewHighlight(start, end, highlightType, 5)
+}
+
 // Overlaps checks if this highlight overlaps with another highlight
 func (h *Highlight) Overlaps(other *Highlight) bool {
 	// If highlights are on different lines
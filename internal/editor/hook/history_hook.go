@@ -74,6 +74,7 @@ func isModifyingCommand(cmdName string) bool {
 		"new_line":             true,
 		"append_end_of_line":   true,
 		"insert_start_of_line": true,
+		"substitute":           true,
 	}
 	return modifyingCommands[cmdName]
 }
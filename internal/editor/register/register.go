@@ -1,7 +1,8 @@
 package register
 
 type Register struct {
-	data string
+	data     string
+	linewise bool
 }
 
 func NewRegister() *Register {
@@ -10,8 +11,20 @@ func NewRegister() *Register {
 
 func (r *Register) Set(text string) {
 	r.data = text
+	r.linewise = false
+}
+
+// SetLines stores whole lines, which are put as new lines rather than into
+// the current one
+func (r *Register) SetLines(text string) {
+	r.data = text
+	r.linewise = true
 }
 
 func (r *Register) Get() string {
 	return r.data
 }
+
+func (r *Register) Linewise() bool {
+	return r.linewise
+}
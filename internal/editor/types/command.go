@@ -8,3 +8,11 @@ type Command interface {
 	Name() string
 	Explain() string
 }
+
+// CommandFactory builds a command from the arguments typed after its name in
+// command mode. It returns nil when the arguments are invalid.
+type CommandFactory func(args string) Command
+
+// Completer returns the values the argument typed so far after a command's
+// name can be completed to, each replacing it as a whole
+type Completer func(args string) []string
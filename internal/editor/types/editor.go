@@ -11,6 +11,7 @@ type Editor interface {
 	Viewport() Viewport
 	Width() int
 	Height() int
+	Mode() state.Mode
 	SetMode(mode state.Mode)
 	HandleCursorMovement()
 	UpdateViewport(width, height int)
@@ -23,5 +24,17 @@ type Editor interface {
 	AddHook(h Hook)
 	RemoveHook(h Hook)
 	GetHooks() []Hook
+	RegisterCommand(name string, factory CommandFactory)
+	// RegisterCompletion sets how the arguments of a command are completed
+	// when tab is pressed in command mode
+	RegisterCompletion(name string, completer Completer)
+	// SetMessage shows text in place of the status line until the next key
+	// press. An empty text clears it.
+	SetMessage(text string, isError bool)
+	// SetSelection remembers the lines of the visual selection a command is
+	// typed for. Selection returns them, inclusive, until command mode is
+	// left.
+	SetSelection(start, end int)
+	Selection() (start, end int, ok bool)
 	Logger() Logger
 }
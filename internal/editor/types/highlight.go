@@ -53,4 +53,20 @@ const (
 	VisualHighlight HighlightType = iota
 	// SearchHighlight for search results
 	SearchHighlight
+	// GitModifiedHighlight for entries changed in the work tree
+	GitModifiedHighlight
+	// GitStagedHighlight for entries with changes in the index only
+	GitStagedHighlight
+	// GitUntrackedHighlight for entries git does not know about
+	GitUntrackedHighlight
+	// GitIgnoredHighlight for entries matched by an ignore rule
+	GitIgnoredHighlight
+	// GitConflictHighlight for entries with unmerged changes
+	GitConflictHighlight
+	// MarkedHighlight for entries marked to be picked
+	MarkedHighlight
+
+	// CustomHighlight is the first of the types defined at runtime with
+	// their own style
+	CustomHighlight HighlightType = 1000
 )
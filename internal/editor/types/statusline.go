@@ -2,4 +2,5 @@ package types
 
 type StatusLine interface {
 	Render(mode string, cursor Cursor, bufferLineCount int, viewX, viewY int, width int) string
+	RenderMessage(text string, isError bool, width int) string
 }
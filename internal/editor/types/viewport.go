@@ -26,6 +26,9 @@ type Viewport interface {
 	ScrollLeft(cols int)
 	ScrollRight(cols int)
 	SetHighlightManager(hm HighlightManager)
+	// SetAnnotations sets text shown at the right edge of lines, keyed by
+	// line number, which is not part of the buffer
+	SetAnnotations(annotations map[int]string)
 	SyncCursors(bufferCursors []Cursor, bufferLineCount int)
 	ScrollHalfPageUp()
 	ScrollHalfPageDown(bufferLineCount int)
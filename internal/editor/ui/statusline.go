@@ -54,6 +54,21 @@ func (s *StatusLine) Render(mode string, cursor types.Cursor, bufferLineCount in
 	)
 }
 
+// RenderMessage renders a message spanning the whole status line
+func (s *StatusLine) RenderMessage(text string, isError bool, width int) string {
+	style := s.styles.GetMessageStyle()
+	if isError {
+		style = s.styles.GetErrorStyle()
+	}
+
+	// Only the first line fits
+	text, _, _ = strings.Cut(text, "\n")
+	if lipgloss.Width(text) > width {
+		text = string([]rune(text)[:max(0, width-1)]) + "…"
+	}
+	return style.Render(text + strings.Repeat(" ", max(0, width-lipgloss.Width(text))))
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -44,6 +44,17 @@ var (
 	progressStyle = baseStatusStyle.
 			Background(lipgloss.Color("#444444")).
 			Foreground(lipgloss.Color("#d0d0d0"))
+
+	messageStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#d0d0d0"))
+
+	errorStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#ff5f5f"))
+
+	// Text shown next to lines without being part of them
+	annotationStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#5c6370"))
 )
 
 // StatusLineStyle provides styling functions for the status line
@@ -79,3 +90,13 @@ func (s *StatusLineStyle) GetPositionStyle() lipgloss.Style {
 func (s *StatusLineStyle) GetProgressStyle() lipgloss.Style {
 	return progressStyle
 }
+
+// GetMessageStyle returns the style for messages shown in the status line
+func (s *StatusLineStyle) GetMessageStyle() lipgloss.Style {
+	return messageStyle
+}
+
+// GetErrorStyle returns the style for error messages
+func (s *StatusLineStyle) GetErrorStyle() lipgloss.Style {
+	return errorStyle
+}
@@ -32,6 +32,7 @@ type Viewport struct {
 	cursorStyle      *buffer.CursorStyle
 	mode             state.Mode
 	highlightManager types.HighlightManager
+	annotations      map[int]string // text shown at the right edge, by line
 }
 
 // NewViewport creates a new viewport with the given dimensions
@@ -311,31 +312,40 @@ func (vp *Viewport) collectStyleRanges(lineNumber int, contentLength int) ([]Sty
 	return ranges, cursors
 }
 
-// mergeHighlightRanges handles merging overlapping highlights
+// mergeHighlightRanges flattens overlapping highlights. Ranges come in
+// priority order, so where they overlap the earlier one is kept and later
+// ones only fill the gaps around it.
 func (vp *Viewport) mergeHighlightRanges(ranges []StyleRange) []StyleRange {
 	if len(ranges) == 0 {
 		return nil
 	}
 
-	// Sort highlight ranges by start position
-	sort.Slice(ranges, func(i, j int) bool {
-		return ranges[i].start < ranges[j].start
-	})
-
-	// Merge overlapping ranges
 	merged := make([]StyleRange, 0, len(ranges))
-	current := ranges[0]
-	for i := 1; i < len(ranges); i++ {
-		if ranges[i].start <= current.end {
-			if ranges[i].end > current.end {
-				current.end = ranges[i].end
+	for _, r := range ranges {
+		pieces := []StyleRange{r}
+		for _, m := range merged {
+			var rest []StyleRange
+			for _, p := range pieces {
+				if p.end <= m.start || p.start >= m.end {
+					rest = append(rest, p)
+					continue
+				}
+				if p.start < m.start {
+					rest = append(rest, StyleRange{start: p.start, end: m.start, style: p.style})
+				}
+				if p.end > m.end {
+					rest = append(rest, StyleRange{start: m.end, end: p.end, style: p.style})
+				}
 			}
-		} else {
-			merged = append(merged, current)
-			current = ranges[i]
+			pieces = rest
 		}
+		merged = append(merged, pieces...)
 	}
-	merged = append(merged, current)
+
+	// Sort highlight ranges by start position
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].start < merged[j].start
+	})
 
 	return merged
 }
@@ -415,12 +425,28 @@ func (vp *Viewport) renderLine(content string, lineNumber int) string {
 	visibleContent := vp.prepareVisibleContent(content)
 	highlightRanges, cursors := vp.collectStyleRanges(lineNumber, len(content))
 
+	// The annotation replaces the end of the padding, when the line leaves
+	// room for it
+	annotation := vp.annotations[lineNumber]
+	if width := lipgloss.Width(annotation); annotation != "" && len(content)-vp.offset.Column()+1+width <= vp.width {
+		visibleContent = visibleContent[:vp.width-width]
+		annotation = annotationStyle.Render(annotation)
+	} else {
+		annotation = ""
+	}
+
 	if len(highlightRanges) == 0 && len(cursors) == 0 {
-		return visibleContent
+		return visibleContent + annotation
 	}
 
 	mergedHighlights := vp.mergeHighlightRanges(highlightRanges)
-	return vp.applyStyles(visibleContent, mergedHighlights, cursors)
+	return vp.applyStyles(visibleContent, mergedHighlights, cursors) + annotation
+}
+
+// SetAnnotations sets text shown at the right edge of lines, keyed by line
+// number. It is left out on lines too long to fit it.
+func (vp *Viewport) SetAnnotations(annotations map[int]string) {
+	vp.annotations = annotations
 }
 
 // createEmptyLine creates an empty line with proper formatting
@@ -0,0 +1,136 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const (
+	// DefaultMaxSize is the size a log file may grow to before it is
+	// rotated
+	DefaultMaxSize = 1 << 20
+	// DefaultKeep is how many rotated files are kept next to the current one
+	DefaultKeep = 3
+)
+
+// Log appends one JSON object per line to a file. Once the file would grow
+// past maxSize it is renamed to file.1, shifting older ones up to file.<keep>
+// and dropping the oldest.
+type Log struct {
+	mu      sync.Mutex
+	file    string
+	maxSize int64
+	keep    int
+}
+
+func New(file string, maxSize int64, keep int) types.AuditLog {
+	return &Log{
+		file:    file,
+		maxSize: maxSize,
+		keep:    keep,
+	}
+}
+
+func (l *Log) Record(entry types.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.file), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	if info, err := os.Stat(l.file); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(l.file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotate shifts file.N to file.N+1, dropping the one past keep, and moves
+// the current file to file.1
+func (l *Log) rotate() error {
+	if l.keep < 1 {
+		return os.Remove(l.file)
+	}
+
+	if err := os.Remove(l.rotated(l.keep)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for i := l.keep - 1; i >= 1; i-- {
+		if err := os.Rename(l.rotated(i), l.rotated(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(l.file, l.rotated(1))
+}
+
+func (l *Log) rotated(n int) string {
+	return fmt.Sprintf("%s.%d", l.file, n)
+}
+
+func (l *Log) Entries() ([]types.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var entries []types.AuditEntry
+	files := []string{l.file}
+	for i := 1; i <= l.keep; i++ {
+		files = append(files, l.rotated(i))
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		read, err := readEntries(files[i])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, read...)
+	}
+	return entries, nil
+}
+
+// readEntries reads a log file, skipping lines that are not valid entries
+// such as one cut short by a crash
+func readEntries(file string) ([]types.AuditEntry, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []types.AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var entry types.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
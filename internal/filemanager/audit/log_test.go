@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogTestSuite struct {
+	suite.Suite
+	file string
+}
+
+func (s *LogTestSuite) SetupTest() {
+	s.file = filepath.Join(s.T().TempDir(), "grease", "oplog.jsonl")
+}
+
+func entry(n int) types.AuditEntry {
+	return types.AuditEntry{
+		Time:   time.Date(2024, 5, 1, 10, 0, n, 0, time.UTC),
+		Type:   "delete",
+		Source: fmt.Sprintf("/tmp/file%02d", n),
+		Result: "ok",
+		Cwd:    "/tmp",
+	}
+}
+
+func (s *LogTestSuite) sources(entries []types.AuditEntry) []string {
+	var sources []string
+	for _, e := range entries {
+		sources = append(sources, e.Source)
+	}
+	return sources
+}
+
+func (s *LogTestSuite) TestRecordAndRead() {
+	log := New(s.file, DefaultMaxSize, DefaultKeep)
+	s.Require().NoError(log.Record(entry(1)))
+	s.Require().NoError(log.Record(entry(2)))
+
+	entries, err := log.Entries()
+	s.Require().NoError(err)
+	s.Equal([]types.AuditEntry{entry(1), entry(2)}, entries)
+}
+
+func (s *LogTestSuite) TestRotation() {
+	// Room for two entries per file
+	line, _ := os.ReadFile(s.writeOne())
+	log := New(s.file, int64(2*len(line)), 2)
+
+	for n := 1; n <= 7; n++ {
+		s.Require().NoError(log.Record(entry(n)))
+	}
+
+	entries, err := log.Entries()
+	s.Require().NoError(err)
+	s.Equal([]string{"/tmp/file03", "/tmp/file04", "/tmp/file05", "/tmp/file06", "/tmp/file07"}, s.sources(entries))
+
+	for _, name := range []string{s.file, s.file + ".1", s.file + ".2"} {
+		s.FileExists(name)
+	}
+	s.NoFileExists(s.file + ".3")
+}
+
+func (s *LogTestSuite) TestSkipsBrokenLines() {
+	log := New(s.file, DefaultMaxSize, DefaultKeep)
+	s.Require().NoError(log.Record(entry(1)))
+
+	f, err := os.OpenFile(s.file, os.O_WRONLY|os.O_APPEND, 0)
+	s.Require().NoError(err)
+	_, err = f.WriteString("{\"time\":\"2024-05\n")
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+	s.Require().NoError(log.Record(entry(2)))
+
+	entries, err := log.Entries()
+	s.Require().NoError(err)
+	s.Equal([]string{"/tmp/file01", "/tmp/file02"}, s.sources(entries))
+}
+
+// writeOne records a single entry in a log of its own and returns its file
+func (s *LogTestSuite) writeOne() string {
+	file := filepath.Join(s.T().TempDir(), "one.jsonl")
+	s.Require().NoError(New(file, DefaultMaxSize, DefaultKeep).Record(entry(1)))
+	return file
+}
+
+func TestLogSuite(t *testing.T) {
+	suite.Run(t, new(LogTestSuite))
+}
@@ -0,0 +1,103 @@
+package filemanager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gunererd/grease/internal/filemanager/compare"
+	"github.com/gunererd/grease/internal/filemanager/directory"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/reconcile"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Plan returns the operations that make dir match an edited listing of it,
+// read one entry per line with directories ending in "/". See
+// reconcile.Plan for how the lines are matched with the entries.
+func Plan(dir string, listing io.Reader, opts ...Option) ([]types.Operation, error) {
+	options := newOptions(opts)
+	logger, err := options.logger()
+	if err != nil {
+		return nil, err
+	}
+	return plan(dir, listing, options.FileSystem, logger)
+}
+
+// Apply executes the operations Plan returns without a UI. Existing targets
+// are handled as set by WithConflictResolution, and fail when it is left
+// to ask. Asking on failure is not possible either and stops instead.
+func Apply(dir string, listing io.Reader, opts ...Option) ([]types.OperationResult, error) {
+	options := newOptions(opts)
+	logger, err := options.logger()
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := plan(dir, listing, options.FileSystem, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := options.OnFailure
+	if policy == types.AskOnFailure {
+		policy = types.StopOnFailure
+	}
+	dirManager := directory.NewDirectoryManager(dir, options.FileSystem, logger)
+	opManager := operation.NewOperationManager(dirManager, options.FileSystem, options.templates(), policy, options.OnConflict, options.auditLog(), logger)
+	for _, op := range ops {
+		opManager.QueueOperation(op)
+	}
+
+	var resolve types.ConflictResolver
+	if resolution := options.OnConflict; resolution != types.ConflictAsk {
+		resolve = func(types.Conflict) types.ConflictResolution {
+			return resolution
+		}
+	}
+	return opManager.ExecuteOperations(context.Background(), nil, resolve), nil
+}
+
+// Compare returns how the tree below right differs from the one below left,
+// see compare.Dirs
+func Compare(left, right string, opts ...Option) ([]compare.Difference, error) {
+	options := newOptions(opts)
+
+	var err error
+	if left, err = resolvePath(options.FileSystem, left); err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if right, err = resolvePath(options.FileSystem, right); err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return compare.Dirs(context.Background(), options.FileSystem, left, right)
+}
+
+func plan(dir string, listing io.Reader, fs types.FileSystem, logger types.Logger) ([]types.Operation, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	entries, err := directory.NewDirectoryManager(dir, fs, logger).ReadDirectory()
+	if err != nil {
+		return nil, err
+	}
+	original := make([]string, len(entries))
+	for i, entry := range entries {
+		original[i] = entry.Name()
+	}
+
+	var edited []string
+	scanner := bufio.NewScanner(listing)
+	for scanner.Scan() {
+		edited = append(edited, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read listing: %w", err)
+	}
+
+	return reconcile.Plan(dir, original, edited)
+}
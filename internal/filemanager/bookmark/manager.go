@@ -0,0 +1,155 @@
+package bookmark
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+var ErrInvalidMark = errors.New("invalid mark, expected a-z")
+
+// Manager keeps directory bookmarks in memory and mirrors every change to a
+// file with one "<mark> <path>" line per bookmark.
+type Manager struct {
+	file      string
+	bookmarks map[rune]string
+	logger    types.Logger
+}
+
+func NewBookmarkManager(file string, logger types.Logger) types.BookmarkManager {
+	m := &Manager{
+		file:      file,
+		bookmarks: make(map[rune]string),
+		logger:    logger,
+	}
+
+	if err := m.load(); err != nil {
+		logger.Println("Failed to load bookmarks:", err)
+	}
+
+	return m
+}
+
+func (m *Manager) Set(mark rune, path string) error {
+	if !IsValidMark(mark) {
+		return ErrInvalidMark
+	}
+
+	m.bookmarks[mark] = path
+	return m.save()
+}
+
+func (m *Manager) Get(mark rune) (string, bool) {
+	path, ok := m.bookmarks[mark]
+	return path, ok
+}
+
+func (m *Manager) All() map[rune]string {
+	result := make(map[rune]string, len(m.bookmarks))
+	for mark, path := range m.bookmarks {
+		result[mark] = path
+	}
+	return result
+}
+
+func (m *Manager) Replace(bookmarks map[rune]string) error {
+	for mark := range bookmarks {
+		if !IsValidMark(mark) {
+			return fmt.Errorf("%w: %q", ErrInvalidMark, mark)
+		}
+	}
+
+	m.bookmarks = make(map[rune]string, len(bookmarks))
+	for mark, path := range bookmarks {
+		m.bookmarks[mark] = path
+	}
+	return m.save()
+}
+
+// IsValidMark reports whether r can be used as a bookmark name
+func IsValidMark(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
+
+// Format renders bookmarks as sorted "<mark> <path>" lines
+func Format(bookmarks map[rune]string) []string {
+	marks := make([]rune, 0, len(bookmarks))
+	for mark := range bookmarks {
+		marks = append(marks, mark)
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i] < marks[j] })
+
+	lines := make([]string, 0, len(marks))
+	for _, mark := range marks {
+		lines = append(lines, fmt.Sprintf("%c %s", mark, bookmarks[mark]))
+	}
+	return lines
+}
+
+// Parse reads "<mark> <path>" lines, skipping blank ones
+func Parse(lines []string) (map[rune]string, error) {
+	bookmarks := make(map[rune]string)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		mark, path, ok := strings.Cut(line, " ")
+		path = strings.TrimSpace(path)
+		if !ok || len(mark) != 1 || !IsValidMark(rune(mark[0])) || path == "" {
+			return nil, fmt.Errorf("line %d: expected \"<a-z> <path>\", got %q", i+1, line)
+		}
+		bookmarks[rune(mark[0])] = path
+	}
+	return bookmarks, nil
+}
+
+func (m *Manager) load() error {
+	f, err := os.Open(m.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	bookmarks, err := Parse(lines)
+	if err != nil {
+		return err
+	}
+	m.bookmarks = bookmarks
+	return nil
+}
+
+func (m *Manager) save() error {
+	if err := os.MkdirAll(filepath.Dir(m.file), 0755); err != nil {
+		return fmt.Errorf("failed to create bookmark directory: %w", err)
+	}
+
+	content := strings.Join(Format(m.bookmarks), "\n")
+	if content != "" {
+		content += "\n"
+	}
+
+	if err := os.WriteFile(m.file, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write bookmarks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package bookmark
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ManagerTestSuite struct {
+	suite.Suite
+	file string
+}
+
+func (s *ManagerTestSuite) SetupTest() {
+	s.file = filepath.Join(s.T().TempDir(), "grease", "bookmarks")
+}
+
+func (s *ManagerTestSuite) manager() *Manager {
+	return NewBookmarkManager(s.file, log.New(io.Discard, "", 0)).(*Manager)
+}
+
+func (s *ManagerTestSuite) TestSetIsSaved() {
+	s.Require().NoError(s.manager().Set('w', "/work"))
+	s.Require().NoError(s.manager().Set('h', "/home"))
+
+	content, err := os.ReadFile(s.file)
+	s.Require().NoError(err)
+	s.Equal("h /home\nw /work\n", string(content))
+
+	path, ok := s.manager().Get('w')
+	s.True(ok)
+	s.Equal("/work", path)
+}
+
+func (s *ManagerTestSuite) TestInvalidMark() {
+	m := s.manager()
+	s.ErrorIs(m.Set('A', "/work"), ErrInvalidMark)
+	s.ErrorIs(m.Replace(map[rune]string{'1': "/work"}), ErrInvalidMark)
+	s.Empty(m.All())
+}
+
+func (s *ManagerTestSuite) TestReplace() {
+	m := s.manager()
+	s.Require().NoError(m.Set('a', "/a"))
+	s.Require().NoError(m.Replace(map[rune]string{'b': "/b"}))
+
+	s.Equal(map[rune]string{'b': "/b"}, s.manager().All())
+}
+
+func (s *ManagerTestSuite) TestParse() {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected map[rune]string
+		wantErr  bool
+	}{
+		{name: "marks and paths", lines: []string{"a /a", "", "b  /with space "}, expected: map[rune]string{'a': "/a", 'b': "/with space"}},
+		{name: "missing path", lines: []string{"a"}, wantErr: true},
+		{name: "upper case mark", lines: []string{"A /a"}, wantErr: true},
+		{name: "long mark", lines: []string{"ab /a"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			bookmarks, err := Parse(tt.lines)
+			if tt.wantErr {
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Equal(tt.expected, bookmarks)
+		})
+	}
+}
+
+// Edits that do not parse leave the bookmarks as they were
+func (s *ManagerTestSuite) TestScratchWrite() {
+	m := s.manager()
+	s.Require().NoError(m.Set('a', "/a"))
+	scratch := NewScratch(m, nil)
+
+	s.Error(scratch.Write([]string{"a /a", "not a bookmark"}))
+	s.Equal(map[rune]string{'a': "/a"}, m.All())
+
+	s.Require().NoError(scratch.Write([]string{"c /c"}))
+	lines, err := scratch.Lines()
+	s.Require().NoError(err)
+	s.Equal([]string{"c /c"}, lines)
+}
+
+func TestManagerSuite(t *testing.T) {
+	suite.Run(t, new(ManagerTestSuite))
+}
@@ -0,0 +1,47 @@
+package bookmark
+
+import (
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Scratch lists bookmarks for editing. Enter jumps to the bookmark under the
+// cursor and :w replaces all bookmarks with the edited lines.
+type Scratch struct {
+	bookmarks types.BookmarkManager
+	loadDir   func(string) error
+}
+
+func NewScratch(bookmarks types.BookmarkManager, loadDir func(string) error) types.Scratch {
+	return &Scratch{
+		bookmarks: bookmarks,
+		loadDir:   loadDir,
+	}
+}
+
+func (s *Scratch) Name() string {
+	return "bookmarks"
+}
+
+func (s *Scratch) Lines() ([]string, error) {
+	return Format(s.bookmarks.All()), nil
+}
+
+func (s *Scratch) Open(line string) error {
+	bookmarks, err := Parse([]string{line})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range bookmarks {
+		return s.loadDir(path)
+	}
+	return nil
+}
+
+func (s *Scratch) Write(lines []string) error {
+	bookmarks, err := Parse(lines)
+	if err != nil {
+		return err
+	}
+	return s.bookmarks.Replace(bookmarks)
+}
@@ -0,0 +1,159 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+)
+
+// PackCommand queues packing the selected entries, or the one under the
+// cursor, into a new archive, e.g. ":pack photos.tar.gz". Like other
+// operations it is applied on :w.
+type PackCommand struct {
+	fm     types.FileManager
+	target string
+}
+
+func NewPackCommand(fm types.FileManager, target string) *PackCommand {
+	return &PackCommand{
+		fm:     fm,
+		target: target,
+	}
+}
+
+func (c *PackCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if !listingShown(c.fm, e, "pack") {
+		return e
+	}
+	target, ok := creatablePath(c.fm, e, "pack", c.target)
+	if !ok {
+		return e
+	}
+	if vfs.ArchiveFormat(target) == "" {
+		e.SetMessage("pack: the archive has to end in .zip, .tar.gz, .tgz or .tar", true)
+		return e
+	}
+
+	entries := selectedEntries(e)
+	if len(entries) == 0 {
+		e.SetMessage("pack: nothing to pack", true)
+		return e
+	}
+	dir := c.fm.DirectoryManager().CurrentPath()
+	sources := make([]string, len(entries))
+	for i, entry := range entries {
+		sources[i] = filepath.Join(dir, entry)
+	}
+
+	c.fm.OperationManager().QueueOperation(operation.NewPack(sources, target))
+	what := fmt.Sprintf("%d entries", len(entries))
+	if len(entries) == 1 {
+		what = entries[0]
+	}
+	e.SetMessage(fmt.Sprintf("Packing %s into %s, :w applies it", what, filepath.Base(target)), false)
+	return e
+}
+
+func (c *PackCommand) Name() string {
+	return "pack"
+}
+
+func (c *PackCommand) Explain() string {
+	return "Pack the selected entries into a .zip or .tar.gz archive"
+}
+
+// ExtractCommand queues unpacking the selected archives, or the one under
+// the cursor, each into a new directory named after it. With an argument a
+// single archive goes into that directory instead. Like other operations
+// it is applied on :w.
+type ExtractCommand struct {
+	fm     types.FileManager
+	target string
+}
+
+func NewExtractCommand(fm types.FileManager, target string) *ExtractCommand {
+	return &ExtractCommand{
+		fm:     fm,
+		target: target,
+	}
+}
+
+func (c *ExtractCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if !listingShown(c.fm, e, "extract") {
+		return e
+	}
+
+	entries := selectedEntries(e)
+	if len(entries) == 0 {
+		e.SetMessage("extract: nothing to extract", true)
+		return e
+	}
+	for _, entry := range entries {
+		if !vfs.IsArchive(entry) {
+			e.SetMessage(fmt.Sprintf("extract: %s is not an archive", entry), true)
+			return e
+		}
+	}
+	if c.target != "" && len(entries) > 1 {
+		e.SetMessage("extract: a target directory takes a single archive", true)
+		return e
+	}
+
+	dir := c.fm.DirectoryManager().CurrentPath()
+	var ops []types.Operation
+	for _, entry := range entries {
+		arg := c.target
+		if arg == "" {
+			arg = filepath.Join(dir, archiveStem(entry))
+		}
+		target, ok := creatablePath(c.fm, e, "extract", arg)
+		if !ok {
+			return e
+		}
+		ops = append(ops, operation.New(types.Extract, filepath.Join(dir, entry), target))
+	}
+
+	for _, op := range ops {
+		c.fm.OperationManager().QueueOperation(op)
+	}
+	if len(ops) == 1 {
+		e.SetMessage(fmt.Sprintf("Extracting %s into %s/, :w applies it", entries[0], filepath.Base(ops[0].Target())), false)
+	} else {
+		e.SetMessage(fmt.Sprintf("Extracting %d archives, :w applies it", len(ops)), false)
+	}
+	return e
+}
+
+func (c *ExtractCommand) Name() string {
+	return "extract"
+}
+
+func (c *ExtractCommand) Explain() string {
+	return "Extract the archive under the cursor into a directory"
+}
+
+// archiveStem is the name of an archive without its extension
+func archiveStem(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// listingShown reports whether the entries of a directory are shown, which
+// command needs to work on them
+func listingShown(fm types.FileManager, e eTypes.Editor, command string) bool {
+	if fm.Scratch() != nil {
+		e.SetMessage(fmt.Sprintf("%s: only works on the entries of a directory listing", command), true)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/bookmark"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+type BookmarksCommand struct {
+	fm types.FileManager
+}
+
+func NewBookmarksCommand(fm types.FileManager) *BookmarksCommand {
+	return &BookmarksCommand{fm: fm}
+}
+
+func (c *BookmarksCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	scratch := bookmark.NewScratch(c.fm.BookmarkManager(), c.fm.LoadDirectory)
+	if err := c.fm.OpenScratch(scratch); err != nil {
+		c.fm.Logger().Println("Failed to open bookmarks:", err)
+	}
+	return e
+}
+
+func (c *BookmarksCommand) Name() string {
+	return "bookmarks"
+}
+
+func (c *BookmarksCommand) Explain() string {
+	return "List and edit directory bookmarks"
+}
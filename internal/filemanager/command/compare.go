@@ -0,0 +1,47 @@
+package command
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// CompareCommand lists how the current directory, on the left, differs from
+// another one on the right. The listing stays open while entries are copied
+// between them with ">" and "<".
+type CompareCommand struct {
+	fm   types.FileManager
+	path string
+}
+
+func NewCompareCommand(fm types.FileManager, path string) *CompareCommand {
+	return &CompareCommand{
+		fm:   fm,
+		path: path,
+	}
+}
+
+func (c *CompareCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if c.path == "" {
+		e.SetMessage("compare: a directory to compare with is needed", true)
+		return e
+	}
+
+	right, err := c.fm.ExpandPath(c.path)
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("compare: %v", err), true)
+		return e
+	}
+
+	c.fm.Compare(right)
+	return e
+}
+
+func (c *CompareCommand) Name() string {
+	return "compare"
+}
+
+func (c *CompareCommand) Explain() string {
+	return "Compare the current directory with another one"
+}
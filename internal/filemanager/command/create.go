@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// MkdirCommand creates a directory along with missing parents, the way a
+// new line ending in "/" does on save
+type MkdirCommand struct {
+	fm   types.FileManager
+	path string
+}
+
+func NewMkdirCommand(fm types.FileManager, path string) *MkdirCommand {
+	return &MkdirCommand{
+		fm:   fm,
+		path: path,
+	}
+}
+
+func (c *MkdirCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	path, ok := creatablePath(c.fm, e, "mkdir", c.path)
+	if !ok {
+		return e
+	}
+	if _, err := c.fm.FileSystem().Lstat(path); err == nil {
+		e.SetMessage(fmt.Sprintf("mkdir: %s already exists", c.path), true)
+		return e
+	}
+
+	c.fm.RunOperations([]types.Operation{operation.New(types.Create, path+"/", "")})
+	return e
+}
+
+func (c *MkdirCommand) Name() string {
+	return "mkdir"
+}
+
+func (c *MkdirCommand) Explain() string {
+	return "Create a directory"
+}
+
+// TouchCommand creates an empty file, or updates the modification time of
+// one that exists
+type TouchCommand struct {
+	fm   types.FileManager
+	path string
+}
+
+func NewTouchCommand(fm types.FileManager, path string) *TouchCommand {
+	return &TouchCommand{
+		fm:   fm,
+		path: path,
+	}
+}
+
+func (c *TouchCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	path, ok := creatablePath(c.fm, e, "touch", c.path)
+	if !ok {
+		return e
+	}
+
+	fs := c.fm.FileSystem()
+	if _, err := fs.Stat(path); err != nil {
+		c.fm.RunOperations([]types.Operation{operation.New(types.Create, path, "")})
+		return e
+	}
+
+	now := time.Now()
+	if err := fs.Chtimes(path, now, now); err != nil {
+		e.SetMessage(fmt.Sprintf("touch: %v", err), true)
+		return e
+	}
+	return NewRefreshCommand(c.fm).Execute(e)
+}
+
+func (c *TouchCommand) Name() string {
+	return "touch"
+}
+
+func (c *TouchCommand) Explain() string {
+	return "Create a file or update its modification time"
+}
+
+// creatablePath expands the argument of command, reporting when there is
+// none or it lies where nothing can be created
+func creatablePath(fm types.FileManager, e eTypes.Editor, command, arg string) (string, bool) {
+	if arg == "" {
+		e.SetMessage(fmt.Sprintf("Usage: :%s path", command), true)
+		return "", false
+	}
+
+	path, err := fm.ExpandPath(arg)
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("%s: %v", command, err), true)
+		return "", false
+	}
+	if fm.FileSystem().ReadOnly(path) {
+		e.SetMessage(fmt.Sprintf("%s: %s is inside an archive", command, arg), true)
+		return "", false
+	}
+	return path, true
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// DuCommand shows the size of every entry next to it, walking directories
+// in the background. ":du!" ignores the totals cached by an earlier walk.
+type DuCommand struct {
+	fm    types.FileManager
+	fresh bool
+}
+
+func NewDuCommand(fm types.FileManager, args string) *DuCommand {
+	return &DuCommand{
+		fm:    fm,
+		fresh: args == "!",
+	}
+}
+
+func (c *DuCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	c.fm.ShowSizes(c.fresh)
+	return e
+}
+
+func (c *DuCommand) Name() string {
+	return "du"
+}
+
+func (c *DuCommand) Explain() string {
+	return "Show the size of the entries"
+}
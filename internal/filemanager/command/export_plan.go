@@ -0,0 +1,86 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/shell"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// ExportPlanCommand writes the queued operations to a shell script instead
+// of executing them. Relative paths are taken from the directory shown, an
+// existing file is only replaced with "!", as in ":export-plan! plan.sh".
+type ExportPlanCommand struct {
+	fm   types.FileManager
+	args string
+}
+
+func NewExportPlanCommand(fm types.FileManager, args string) *ExportPlanCommand {
+	return &ExportPlanCommand{
+		fm:   fm,
+		args: args,
+	}
+}
+
+func (c *ExportPlanCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	force := strings.HasPrefix(c.args, "!")
+	path := strings.TrimSpace(strings.TrimPrefix(c.args, "!"))
+	if path == "" {
+		e.SetMessage("Usage: :export-plan file.sh", true)
+		return e
+	}
+
+	if err := c.fm.QueueEdits(); err != nil {
+		e.SetMessage(err.Error(), true)
+		return e
+	}
+	ops := c.fm.OperationManager().GetPendingOperations()
+	if len(ops) == 0 {
+		e.SetMessage("No pending operations to export", true)
+		return e
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.fm.DirectoryManager().CurrentPath(), path)
+	}
+	fs := c.fm.FileSystem()
+	if _, err := fs.Lstat(path); err == nil && !force {
+		e.SetMessage(fmt.Sprintf("%s exists, :export-plan! replaces it", path), true)
+		return e
+	}
+
+	script, err := shell.Script(fs, c.fm.OperationManager().Templates(), ops)
+	if err == nil {
+		err = writeFile(fs, path, script)
+	}
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("Failed to export plan: %v", err), true)
+		return e
+	}
+
+	e.SetMessage(fmt.Sprintf("Wrote %d operation(s) to %s, they are still pending", len(ops), path), false)
+	return e
+}
+
+func writeFile(fs types.FileSystem, path, content string) error {
+	w, err := fs.Create(path, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *ExportPlanCommand) Name() string {
+	return "export-plan"
+}
+
+func (c *ExportPlanCommand) Explain() string {
+	return "Write the pending operations to a shell script"
+}
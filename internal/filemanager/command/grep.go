@@ -0,0 +1,33 @@
+package command
+
+import (
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// GrepCommand searches the files below the current directory, e.g.
+// ":grep func \w+Command". Enter on a result opens it in $EDITOR.
+type GrepCommand struct {
+	fm      types.FileManager
+	pattern string
+}
+
+func NewGrepCommand(fm types.FileManager, pattern string) *GrepCommand {
+	return &GrepCommand{
+		fm:      fm,
+		pattern: pattern,
+	}
+}
+
+func (c *GrepCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	c.fm.Grep(c.pattern)
+	return e
+}
+
+func (c *GrepCommand) Name() string {
+	return "grep"
+}
+
+func (c *GrepCommand) Explain() string {
+	return "Search the contents of the files below the current directory"
+}
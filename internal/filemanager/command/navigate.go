@@ -0,0 +1,131 @@
+package command
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// CdCommand changes to a directory given relative to the current one, or
+// to the home directory without an argument
+type CdCommand struct {
+	fm   types.FileManager
+	path string
+}
+
+func NewCdCommand(fm types.FileManager, path string) *CdCommand {
+	return &CdCommand{
+		fm:   fm,
+		path: path,
+	}
+}
+
+func (c *CdCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	path := c.path
+	if path == "" {
+		path = "~"
+	}
+
+	dir, err := c.fm.ExpandPath(path)
+	if err == nil {
+		err = c.fm.LoadDirectory(dir)
+	}
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("cd: %v", err), true)
+	}
+	return e
+}
+
+func (c *CdCommand) Name() string {
+	return "cd"
+}
+
+func (c *CdCommand) Explain() string {
+	return "Change to another directory"
+}
+
+// EditCommand opens a directory in the listing or a file in $EDITOR.
+// Without an argument it reads the current directory again, like
+// RefreshCommand.
+type EditCommand struct {
+	fm   types.FileManager
+	path string
+}
+
+func NewEditCommand(fm types.FileManager, path string) *EditCommand {
+	return &EditCommand{
+		fm:   fm,
+		path: path,
+	}
+}
+
+func (c *EditCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if c.path == "" {
+		return NewRefreshCommand(c.fm).Execute(e)
+	}
+
+	path, err := c.fm.ExpandPath(c.path)
+	if err == nil {
+		err = c.fm.Open(path)
+	}
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("e: %v", err), true)
+	}
+	return e
+}
+
+func (c *EditCommand) Name() string {
+	return "e"
+}
+
+func (c *EditCommand) Explain() string {
+	return "Open a directory or edit a file"
+}
+
+// PwdCommand shows the current directory
+type PwdCommand struct {
+	fm types.FileManager
+}
+
+func NewPwdCommand(fm types.FileManager) *PwdCommand {
+	return &PwdCommand{fm: fm}
+}
+
+func (c *PwdCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	e.SetMessage(c.fm.DirectoryManager().CurrentPath(), false)
+	return e
+}
+
+func (c *PwdCommand) Name() string {
+	return "pwd"
+}
+
+func (c *PwdCommand) Explain() string {
+	return "Show the current directory"
+}
+
+// RefreshCommand reads the current directory again, dropping unsaved edits
+// of the listing
+type RefreshCommand struct {
+	fm types.FileManager
+}
+
+func NewRefreshCommand(fm types.FileManager) *RefreshCommand {
+	return &RefreshCommand{fm: fm}
+}
+
+func (c *RefreshCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if err := c.fm.LoadDirectory(c.fm.DirectoryManager().CurrentPath()); err != nil {
+		e.SetMessage(fmt.Sprintf("refresh: %v", err), true)
+	}
+	return e
+}
+
+func (c *RefreshCommand) Name() string {
+	return "refresh"
+}
+
+func (c *RefreshCommand) Explain() string {
+	return "Read the current directory again"
+}
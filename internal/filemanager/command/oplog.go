@@ -0,0 +1,81 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// OplogCommand shows the audit log newest first. With an argument only the
+// operations whose source or target contain it are shown, e.g.
+// ":oplog report.pdf" to find out where a file went.
+type OplogCommand struct {
+	fm     types.FileManager
+	filter string
+}
+
+func NewOplogCommand(fm types.FileManager, filter string) *OplogCommand {
+	return &OplogCommand{
+		fm:     fm,
+		filter: filter,
+	}
+}
+
+func (c *OplogCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	log := c.fm.OperationManager().AuditLog()
+	if log == nil {
+		e.SetMessage("The audit log is turned off", true)
+		return e
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("Failed to read the audit log: %v", err), true)
+		return e
+	}
+
+	var lines []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if c.filter != "" && !strings.Contains(entry.Source, c.filter) && !strings.Contains(entry.Target, c.filter) {
+			continue
+		}
+		lines = append(lines, formatEntry(entry))
+	}
+
+	if len(lines) == 0 {
+		e.SetMessage("No operations were recorded", false)
+		return e
+	}
+	if err := c.fm.OpenScratch(scratch.NewText("oplog", lines)); err != nil {
+		c.fm.Logger().Println("Failed to open audit log:", err)
+	}
+	return e
+}
+
+// formatEntry renders an entry as e.g.
+// "2024-05-01 10:00:00 ok       rename /a/b -> /a/c (in /home/me)"
+func formatEntry(entry types.AuditEntry) string {
+	line := fmt.Sprintf("%s %-8s %s %s", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Result, entry.Type, entry.Source)
+	if entry.Target != "" {
+		line += " -> " + entry.Target
+	}
+	if entry.Error != "" {
+		line += ": " + entry.Error
+	}
+	if entry.Cwd != "" {
+		line += " (in " + entry.Cwd + ")"
+	}
+	return line
+}
+
+func (c *OplogCommand) Name() string {
+	return "oplog"
+}
+
+func (c *OplogCommand) Explain() string {
+	return "Show the executed operations, newest first"
+}
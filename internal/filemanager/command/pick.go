@@ -0,0 +1,139 @@
+package command
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/picker"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// MarkCommand marks the selected entries to be picked, or unmarks those
+// that are marked already. Without a selection it works on the entry under
+// the cursor.
+type MarkCommand struct {
+	fm types.FileManager
+}
+
+func NewMarkCommand(fm types.FileManager) *MarkCommand {
+	return &MarkCommand{fm: fm}
+}
+
+func (c *MarkCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	entries, ok := pickableEntries(c.fm, e)
+	if !ok {
+		return e
+	}
+
+	p := c.fm.Picker()
+	dir := c.fm.DirectoryManager().CurrentPath()
+	for _, entry := range entries {
+		path, isDir := picker.Entry(dir, entry)
+		if err := p.Toggle(path, isDir); err != nil {
+			e.SetMessage(fmt.Sprintf("Cannot pick %s: %v", entry, err), true)
+			return e
+		}
+	}
+	e.SetMessage(fmt.Sprintf("%d marked", len(p.Marks())), false)
+	return e
+}
+
+func (c *MarkCommand) Name() string {
+	return "mark"
+}
+
+func (c *MarkCommand) Explain() string {
+	return "Mark or unmark the selected entries to be picked"
+}
+
+// PickCommand ends picking. Selected entries are picked along with those
+// marked before; without a selection or marks the entry under the cursor is
+// picked, which is how a directory is chosen.
+type PickCommand struct {
+	fm types.FileManager
+}
+
+func NewPickCommand(fm types.FileManager) *PickCommand {
+	return &PickCommand{fm: fm}
+}
+
+func (c *PickCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	p := c.fm.Picker()
+	if _, _, selected := e.Selection(); p != nil && !selected && len(p.Marks()) > 0 {
+		c.fm.FinishPicking()
+		return e
+	}
+
+	entries, ok := pickableEntries(c.fm, e)
+	if !ok {
+		return e
+	}
+
+	dir := c.fm.DirectoryManager().CurrentPath()
+	for _, entry := range entries {
+		path, isDir := picker.Entry(dir, entry)
+		if p.Marked(path) {
+			continue
+		}
+		if err := p.Toggle(path, isDir); err != nil {
+			e.SetMessage(fmt.Sprintf("Cannot pick %s: %v", entry, err), true)
+			return e
+		}
+	}
+	c.fm.FinishPicking()
+	return e
+}
+
+func (c *PickCommand) Name() string {
+	return "pick"
+}
+
+func (c *PickCommand) Explain() string {
+	return "Pick the marked or selected entries and quit"
+}
+
+// pickableEntries returns the selected entries of the listing, or the one
+// under the cursor without a selection, reporting why there are none
+func pickableEntries(fm types.FileManager, e eTypes.Editor) ([]string, bool) {
+	switch {
+	case fm.Picker() == nil:
+		e.SetMessage("Not picking, start grease with --pick", true)
+		return nil, false
+	case fm.Scratch() != nil:
+		e.SetMessage("Only entries of a directory listing can be picked", true)
+		return nil, false
+	case fm.DirectoryManager().IsReadOnly():
+		e.SetMessage("Entries inside archives cannot be picked", true)
+		return nil, false
+	}
+
+	entries := selectedEntries(e)
+	if len(entries) == 0 {
+		e.SetMessage("Nothing to pick", true)
+		return nil, false
+	}
+	return entries, true
+}
+
+// selectedEntries returns the non-empty lines of the visual selection, or
+// the line under the cursor without one
+func selectedEntries(e eTypes.Editor) []string {
+	buf := e.Buffer()
+	start, end, ok := e.Selection()
+	if !ok {
+		cursor, err := buf.GetPrimaryCursor()
+		if err != nil {
+			return nil
+		}
+		start = cursor.GetPosition().Line()
+		end = start
+	}
+
+	var entries []string
+	for line := start; line <= end && line < buf.LineCount(); line++ {
+		if entry, err := buf.GetLine(line); err == nil && entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+type ResultsCommand struct {
+	fm types.FileManager
+}
+
+func NewResultsCommand(fm types.FileManager) *ResultsCommand {
+	return &ResultsCommand{fm: fm}
+}
+
+func (c *ResultsCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	results := c.fm.Results()
+	if len(results) == 0 {
+		e.SetMessage("No operations were applied", false)
+		return e
+	}
+
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		line := fmt.Sprintf("%-8s %s", result.Status, operation.Describe(result.Operation))
+		if result.Err != nil {
+			line += ": " + result.Err.Error()
+		}
+		lines = append(lines, line)
+	}
+
+	if err := c.fm.OpenScratch(scratch.NewText("results", lines)); err != nil {
+		c.fm.Logger().Println("Failed to open results:", err)
+	}
+	return e
+}
+
+func (c *ResultsCommand) Name() string {
+	return "results"
+}
+
+func (c *ResultsCommand) Explain() string {
+	return "Show the outcome of the operations of the last save"
+}
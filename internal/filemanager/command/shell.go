@@ -0,0 +1,56 @@
+package command
+
+import (
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/shell"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// ShellCommand runs a shell command on entries of the listing, see
+// shell.Expand for the placeholders it understands
+type ShellCommand struct {
+	fm   types.FileManager
+	args string
+}
+
+func NewShellCommand(fm types.FileManager, args string) *ShellCommand {
+	return &ShellCommand{
+		fm:   fm,
+		args: args,
+	}
+}
+
+func (c *ShellCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if c.args == "" {
+		e.SetMessage("Usage: :!command, % is the entry, %s the selection, %d the directory", true)
+		return e
+	}
+	if c.fm.Scratch() != nil {
+		e.SetMessage("Shell commands only work on a directory listing", true)
+		return e
+	}
+
+	ctx := shell.Context{Dir: c.fm.DirectoryManager().CurrentPath()}
+	buf := e.Buffer()
+	if cursor, err := buf.GetPrimaryCursor(); err == nil {
+		ctx.Entry, _ = buf.GetLine(cursor.GetPosition().Line())
+	}
+	if start, end, ok := e.Selection(); ok {
+		for line := start; line <= end && line < buf.LineCount(); line++ {
+			if entry, err := buf.GetLine(line); err == nil && entry != "" {
+				ctx.Selection = append(ctx.Selection, entry)
+			}
+		}
+	}
+
+	c.fm.RunShell(shell.Expand(c.args, ctx))
+	return e
+}
+
+func (c *ShellCommand) Name() string {
+	return "!"
+}
+
+func (c *ShellCommand) Explain() string {
+	return "Run a shell command on the entry under the cursor or the selection"
+}
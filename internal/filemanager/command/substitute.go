@@ -0,0 +1,123 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/rename"
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// SubstituteCommand renames entries with a regular expression, on the line
+// under the cursor or on every line of the listing. Only the lines change,
+// saving the listing renames the entries. Nothing is changed when the new
+// names collide, a preview of the collisions is shown instead.
+type SubstituteCommand struct {
+	fm       types.FileManager
+	args     string
+	allLines bool
+}
+
+func NewSubstituteCommand(fm types.FileManager, args string, allLines bool) *SubstituteCommand {
+	return &SubstituteCommand{
+		fm:       fm,
+		args:     args,
+		allLines: allLines,
+	}
+}
+
+func (c *SubstituteCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if !listingShown(c.fm, e, "substitute") {
+		return e
+	}
+	if dir := c.fm.DirectoryManager().CurrentPath(); c.fm.FileSystem().ReadOnly(dir) {
+		e.SetMessage(fmt.Sprintf("substitute: %s is inside an archive", dir), true)
+		return e
+	}
+
+	sub, err := rename.Parse(c.args)
+	if err != nil {
+		e.SetMessage(fmt.Sprintf("substitute: %v", err), true)
+		return e
+	}
+
+	buf := e.Buffer()
+	listing := make([]string, buf.LineCount())
+	for i := range listing {
+		listing[i], _ = buf.GetLine(i)
+	}
+
+	var lines []int
+	if c.allLines {
+		for i := range listing {
+			lines = append(lines, i)
+		}
+	} else {
+		cursor, err := buf.GetPrimaryCursor()
+		if err != nil {
+			return e
+		}
+		lines = []int{cursor.GetPosition().Line()}
+	}
+
+	renames, collisions := rename.Plan(listing, lines, sub)
+	if len(collisions) == 0 {
+		collisions = chained(renames)
+	}
+	if len(collisions) > 0 {
+		preview := []string{fmt.Sprintf("%d collision(s), nothing was renamed:", len(collisions))}
+		for _, collision := range collisions {
+			preview = append(preview, collision.String())
+		}
+		if err := c.fm.OpenScratch(scratch.NewText("substitute", preview)); err != nil {
+			e.SetMessage(fmt.Sprintf("substitute: failed to show collisions: %v", err), true)
+		}
+		return e
+	}
+	if len(renames) == 0 {
+		e.SetMessage("substitute: pattern not found", true)
+		return e
+	}
+
+	for _, r := range renames {
+		if err := buf.ReplaceLine(r.Line, r.To); err != nil {
+			e.SetMessage(fmt.Sprintf("substitute: failed to update line: %v", err), true)
+			return e
+		}
+	}
+
+	e.SetMessage(fmt.Sprintf("Renamed %d line(s), :w applies it", len(renames)), false)
+	return e
+}
+
+// chained reports renames onto the name of another renamed entry. Saving
+// compares the listing with the directory by name, where such a chain looks
+// like deleting the first entry and creating the last one.
+func chained(renames []rename.Rename) []rename.Collision {
+	from := make(map[string]string, len(renames))
+	for _, r := range renames {
+		from[strings.TrimSuffix(r.From, "/")] = r.From
+	}
+
+	var collisions []rename.Collision
+	for _, r := range renames {
+		if other, ok := from[strings.TrimSuffix(r.To, "/")]; ok {
+			collisions = append(collisions, rename.Collision{
+				Target:  r.To,
+				Sources: []string{r.From},
+				Reason:  fmt.Sprintf("%s is renamed as well, rename them one after the other", other),
+			})
+		}
+	}
+	return collisions
+}
+
+func (c *SubstituteCommand) Name() string {
+	return "substitute"
+}
+
+func (c *SubstituteCommand) Explain() string {
+	return fmt.Sprintf("type:<SubstituteCommand>, args:<%s>, allLines:<%t>", c.args, c.allLines)
+}
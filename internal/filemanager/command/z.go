@@ -0,0 +1,38 @@
+package command
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// ZCommand jumps to the most frequently and recently visited directory
+// matching its keywords, e.g. ":z src gr" for ~/src/grease. Without
+// keywords the visited directories are offered in the finder.
+type ZCommand struct {
+	fm       types.FileManager
+	keywords []string
+}
+
+func NewZCommand(fm types.FileManager, keywords []string) *ZCommand {
+	return &ZCommand{
+		fm:       fm,
+		keywords: keywords,
+	}
+}
+
+func (c *ZCommand) Execute(e eTypes.Editor) eTypes.Editor {
+	if err := c.fm.Jump(c.keywords...); err != nil {
+		e.SetMessage(fmt.Sprintf("z: %v", err), true)
+	}
+	return e
+}
+
+func (c *ZCommand) Name() string {
+	return "z"
+}
+
+func (c *ZCommand) Explain() string {
+	return "Jump to a frequently visited directory"
+}
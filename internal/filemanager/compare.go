@@ -0,0 +1,81 @@
+package filemanager
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/compare"
+)
+
+// compareFinishedMsg carries the differences found by a comparison
+type compareFinishedMsg struct {
+	id    int
+	left  string
+	right string
+	diffs []compare.Difference
+	err   error
+}
+
+// Compare lists how the current directory, on the left, differs from right.
+// The trees are compared in the background, and again once entries were
+// copied between them.
+func (fm *Filemanager) Compare(right string) {
+	fm.startCompare(fm.dirManager.CurrentPath(), right)
+	fm.editor.SetMessage(fmt.Sprintf("Comparing with %s…", right), false)
+}
+
+func (fm *Filemanager) startCompare(left, right string) {
+	fm.cancelComparison()
+	ctx, cancel := context.WithCancel(context.Background())
+	fm.cancelCompare = cancel
+	fm.compareID++
+
+	id, fsys := fm.compareID, fm.fs
+	fm.queueCmd(func() tea.Msg {
+		diffs, err := compare.Dirs(ctx, fsys, left, right)
+		return compareFinishedMsg{id: id, left: left, right: right, diffs: diffs, err: err}
+	})
+}
+
+func (fm *Filemanager) finishCompare(msg compareFinishedMsg) {
+	if msg.id != fm.compareID {
+		return
+	}
+	fm.cancelComparison()
+
+	if msg.err != nil {
+		fm.editor.SetMessage(fmt.Sprintf("compare: %v", msg.err), true)
+		return
+	}
+
+	if s, ok := fm.scratch.(*compare.Scratch); ok {
+		if left, right := s.Dirs(); left != msg.left || right != msg.right {
+			return
+		}
+		// Compared again after copies, the listing stays open
+		s.SetDifferences(msg.diffs)
+		fm.refreshScratch()
+	} else {
+		if fm.scratch != nil || fm.dirManager.CurrentPath() != msg.left {
+			return
+		}
+		if len(msg.diffs) == 0 {
+			fm.editor.SetMessage(fmt.Sprintf("No differences to %s", msg.right), false)
+			return
+		}
+		if err := fm.OpenScratch(compare.NewScratch(fm.fs, msg.left, msg.right, msg.diffs, fm.RunOperations)); err != nil {
+			fm.editor.SetMessage(fmt.Sprintf("compare: %v", err), true)
+			return
+		}
+	}
+	fm.editor.SetMessage(fmt.Sprintf("%d difference(s) to %s, > copies there and < from there", len(msg.diffs), msg.right), false)
+}
+
+// cancelComparison stops a comparison started by Compare
+func (fm *Filemanager) cancelComparison() {
+	if fm.cancelCompare != nil {
+		fm.cancelCompare()
+		fm.cancelCompare = nil
+	}
+}
@@ -0,0 +1,230 @@
+// Package compare finds the entries in which two directory trees differ
+package compare
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+type Status int
+
+const (
+	// OnlyLeft entries are missing on the right
+	OnlyLeft Status = iota
+	// OnlyRight entries are missing on the left
+	OnlyRight
+	// TypeDiffers when one side is e.g. a directory and the other a file
+	TypeDiffers
+	SizeDiffers
+	// ContentDiffers when files of the same size hash differently, or
+	// symlinks point elsewhere
+	ContentDiffers
+	// TimeDiffers when the content is the same but not the modification
+	// time
+	TimeDiffers
+)
+
+var statusNames = []string{"left-only", "right-only", "type", "size", "content", "mtime"}
+
+// statusWidth is how wide the status column of a formatted line is
+const statusWidth = 10
+
+func (s Status) String() string {
+	if int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return fmt.Sprintf("status(%d)", int(s))
+}
+
+// Difference is an entry that is not the same on both sides. Path is
+// relative to the compared directories, with a trailing "/" for
+// directories.
+type Difference struct {
+	Path   string
+	Status Status
+}
+
+// Dirs compares the trees below left and right. Directories found on both
+// sides are descended into, those found on one side only are reported
+// once. Files of the same size are hashed, as a modification time can be
+// kept by a copy or too coarse to tell edits apart.
+// Symlinks are compared by their target, never followed. It stops with the
+// context's error once ctx is done.
+func Dirs(ctx context.Context, fsys types.FileSystem, left, right string) ([]Difference, error) {
+	var diffs []Difference
+	if err := compareDir(ctx, fsys, left, right, "", &diffs); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+func compareDir(ctx context.Context, fsys types.FileSystem, left, right, rel string, diffs *[]Difference) error {
+	leftEntries, err := readDir(fsys, path.Join(left, rel))
+	if err != nil {
+		return err
+	}
+	rightEntries, err := readDir(fsys, path.Join(right, rel))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(leftEntries)+len(rightEntries))
+	for name := range leftEntries {
+		names = append(names, name)
+	}
+	for name := range rightEntries {
+		if _, ok := leftEntries[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entryRel := path.Join(rel, name)
+		l, inLeft := leftEntries[name]
+		r, inRight := rightEntries[name]
+
+		switch {
+		case !inRight:
+			*diffs = append(*diffs, Difference{Path: display(entryRel, l), Status: OnlyLeft})
+		case !inLeft:
+			*diffs = append(*diffs, Difference{Path: display(entryRel, r), Status: OnlyRight})
+		case l.Mode().Type() != r.Mode().Type():
+			*diffs = append(*diffs, Difference{Path: entryRel, Status: TypeDiffers})
+		case l.IsDir():
+			if err := compareDir(ctx, fsys, left, right, entryRel, diffs); err != nil {
+				return err
+			}
+		default:
+			status, same, err := compareFiles(ctx, fsys, path.Join(left, entryRel), path.Join(right, entryRel), l, r)
+			if err != nil {
+				return err
+			}
+			if !same {
+				*diffs = append(*diffs, Difference{Path: entryRel, Status: status})
+			}
+		}
+	}
+	return nil
+}
+
+func readDir(fsys types.FileSystem, dir string) (map[string]fs.FileInfo, error) {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]fs.FileInfo, len(entries))
+	for _, entry := range entries {
+		info, err := fsys.Lstat(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		infos[entry.Name()] = info
+	}
+	return infos, nil
+}
+
+func compareFiles(ctx context.Context, fsys types.FileSystem, left, right string, l, r fs.FileInfo) (Status, bool, error) {
+	if l.Mode()&fs.ModeSymlink != 0 {
+		lt, err := fsys.Readlink(left)
+		if err != nil {
+			return 0, false, err
+		}
+		rt, err := fsys.Readlink(right)
+		if err != nil {
+			return 0, false, err
+		}
+		return ContentDiffers, lt == rt, nil
+	}
+	// Opening a fifo or a device may block or read forever, they are
+	// told apart by their type alone
+	if !l.Mode().IsRegular() {
+		return 0, true, nil
+	}
+
+	if l.Size() != r.Size() {
+		return SizeDiffers, false, nil
+	}
+
+	lh, err := hash(ctx, fsys, left)
+	if err != nil {
+		return 0, false, err
+	}
+	rh, err := hash(ctx, fsys, right)
+	if err != nil {
+		return 0, false, err
+	}
+	if !bytes.Equal(lh, rh) {
+		return ContentDiffers, false, nil
+	}
+	return TimeDiffers, l.ModTime().Equal(r.ModTime()), nil
+}
+
+func hash(ctx context.Context, fsys types.FileSystem, file string) ([]byte, error) {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, &contextReader{ctx: ctx, r: f}); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// contextReader gives up once its context is done, so that hashing a large
+// file can be cancelled
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+func display(rel string, info fs.FileInfo) string {
+	if info.IsDir() {
+		return rel + "/"
+	}
+	return rel
+}
+
+// Format renders d as one line, the status in a column of its own followed
+// by the path, e.g. "size       docs/report.pdf"
+func Format(d Difference) string {
+	return fmt.Sprintf("%-*s %s", statusWidth, d.Status, d.Path)
+}
+
+// Parse reads a line rendered by Format back
+func Parse(line string) (Difference, error) {
+	if len(line) <= statusWidth+1 {
+		return Difference{}, fmt.Errorf("not a difference: %q", line)
+	}
+
+	name := strings.TrimSpace(line[:statusWidth])
+	for i, statusName := range statusNames {
+		if statusName == name {
+			return Difference{Path: line[statusWidth+1:], Status: Status(i)}, nil
+		}
+	}
+	return Difference{}, fmt.Errorf("not a difference: %q", line)
+}
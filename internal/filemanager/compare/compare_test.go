@@ -0,0 +1,92 @@
+package compare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type CompareTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *CompareTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/left/sub", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/right/sub", 0755))
+}
+
+func (s *CompareTestSuite) writeFile(path, content string, mtime time.Time) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(content))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+	s.Require().NoError(s.fs.Chtimes(path, mtime, mtime))
+}
+
+func (s *CompareTestSuite) TestDirs() {
+	then := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := then.Add(time.Hour)
+
+	s.writeFile("/left/same", "same", then)
+	s.writeFile("/right/same", "same", then)
+	s.writeFile("/left/touched", "same", then)
+	s.writeFile("/right/touched", "same", later)
+	s.writeFile("/left/sub/edited", "left", then)
+	s.writeFile("/right/sub/edited", "rght", then)
+	s.writeFile("/left/grown", "a", then)
+	s.writeFile("/right/grown", "ab", then)
+	s.writeFile("/left/kind", "", then)
+	s.Require().NoError(s.fs.Mkdir("/right/kind", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/left/only/deep", 0755))
+	s.writeFile("/right/sub/new", "", then)
+	s.Require().NoError(s.fs.Symlink("same", "/left/link"))
+	s.Require().NoError(s.fs.Symlink("grown", "/right/link"))
+
+	diffs, err := Dirs(context.Background(), s.fs, "/left", "/right")
+	s.Require().NoError(err)
+	s.Equal([]Difference{
+		{Path: "grown", Status: SizeDiffers},
+		{Path: "kind", Status: TypeDiffers},
+		{Path: "link", Status: ContentDiffers},
+		{Path: "only/", Status: OnlyLeft},
+		{Path: "sub/edited", Status: ContentDiffers},
+		{Path: "sub/new", Status: OnlyRight},
+		{Path: "touched", Status: TimeDiffers},
+	}, diffs)
+}
+
+func (s *CompareTestSuite) TestFormatAndParse() {
+	tests := []struct {
+		line string
+		want Difference
+	}{
+		{"left-only  only/", Difference{Path: "only/", Status: OnlyLeft}},
+		{"right-only sub/new", Difference{Path: "sub/new", Status: OnlyRight}},
+		{"size        spaced name", Difference{Path: " spaced name", Status: SizeDiffers}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.line, func() {
+			d, err := Parse(tt.line)
+			s.Require().NoError(err)
+			s.Equal(tt.want, d)
+			s.Equal(tt.line, Format(d))
+		})
+	}
+
+	for _, line := range []string{"", "size", "renamed    a"} {
+		_, err := Parse(line)
+		s.Error(err, line)
+	}
+}
+
+func TestCompareSuite(t *testing.T) {
+	suite.Run(t, new(CompareTestSuite))
+}
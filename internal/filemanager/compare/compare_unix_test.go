@@ -0,0 +1,27 @@
+//go:build unix
+
+package compare
+
+import (
+	"context"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+)
+
+// Opening a fifo blocks until a writer shows up, it must not be hashed
+func (s *CompareTestSuite) TestFifo() {
+	dir := s.T().TempDir()
+	for _, side := range []string{"left", "right"} {
+		s.Require().NoError(syscall.Mkdir(filepath.Join(dir, side), 0755))
+		s.Require().NoError(syscall.Mkfifo(filepath.Join(dir, side, "pipe"), 0644))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	diffs, err := Dirs(ctx, vfs.NewOS(), filepath.Join(dir, "left"), filepath.Join(dir, "right"))
+	s.Require().NoError(err)
+	s.Empty(diffs)
+}
@@ -0,0 +1,93 @@
+package compare
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Scratch lists the differences between two directories. ">" copies the
+// entry under the cursor from the left to the right and "<" the other way,
+// an existing target is handled like any other conflict.
+type Scratch struct {
+	fs    types.FileSystem
+	left  string
+	right string
+	diffs []Difference
+	run   func([]types.Operation)
+}
+
+// NewScratch lists diffs, the differences found between left and right,
+// run executes the copies. Comparing takes as long as reading both trees,
+// so it is left to the caller, as is comparing again once copies are done.
+func NewScratch(fs types.FileSystem, left, right string, diffs []Difference, run func([]types.Operation)) types.Scratch {
+	return &Scratch{
+		fs:    fs,
+		left:  left,
+		right: right,
+		diffs: diffs,
+		run:   run,
+	}
+}
+
+func (s *Scratch) Name() string {
+	return "compare"
+}
+
+// Dirs returns the compared directories
+func (s *Scratch) Dirs() (left, right string) {
+	return s.left, s.right
+}
+
+// SetDifferences replaces the differences listed, such as after comparing
+// again
+func (s *Scratch) SetDifferences(diffs []Difference) {
+	s.diffs = diffs
+}
+
+func (s *Scratch) Lines() ([]string, error) {
+	lines := make([]string, len(s.diffs))
+	for i, d := range s.diffs {
+		lines[i] = Format(d)
+	}
+	return lines, nil
+}
+
+func (s *Scratch) Open(line string) error {
+	return nil
+}
+
+func (s *Scratch) Write(lines []string) error {
+	return scratch.ErrReadOnly
+}
+
+func (s *Scratch) Key(key, line string) (bool, error) {
+	from, to, missing := s.left, s.right, OnlyRight
+	switch key {
+	case ">":
+	case "<":
+		from, to, missing = s.right, s.left, OnlyLeft
+	default:
+		return false, nil
+	}
+
+	d, err := Parse(line)
+	if err != nil {
+		return true, err
+	}
+	if d.Status == missing {
+		return true, fmt.Errorf("%s does not exist on that side", d.Path)
+	}
+	if s.fs.ReadOnly(to) {
+		return true, fmt.Errorf("%s cannot be modified", to)
+	}
+
+	// Copies go into the target directory under the same name
+	rel := strings.TrimSuffix(d.Path, "/")
+	s.run([]types.Operation{operation.New(types.Copy, path.Join(from, rel), path.Join(to, path.Dir(rel)))})
+	return true, nil
+}
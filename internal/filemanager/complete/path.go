@@ -0,0 +1,61 @@
+// Package complete completes file system paths typed as command arguments
+package complete
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Paths returns the paths typed can be completed to. Relative paths are
+// looked up in dir and a leading "~" in home, but the candidates keep them
+// as typed. Directories end in "/" so that completion can go on into them,
+// and hidden entries are only offered once their leading "." is typed.
+func Paths(fsys types.FileSystem, dir, home, typed string, dirsOnly bool) []string {
+	if typed == "~" {
+		return []string{"~/"}
+	}
+
+	// The part up to the last "/" stays as typed, the rest is matched
+	typedDir, prefix := "", typed
+	if i := strings.LastIndex(typed, "/"); i >= 0 {
+		typedDir, prefix = typed[:i+1], typed[i+1:]
+	}
+
+	lookup := typedDir
+	switch {
+	case lookup == "~/" || strings.HasPrefix(lookup, "~/"):
+		lookup = filepath.Join(home, lookup[2:])
+	case !filepath.IsAbs(lookup):
+		lookup = filepath.Join(dir, lookup)
+	}
+
+	entries, err := fsys.ReadDir(lookup)
+	if err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			// Links to directories are completed like them
+			info, err := fsys.Stat(filepath.Join(lookup, name))
+			isDir = err == nil && info.IsDir()
+		}
+		switch {
+		case isDir:
+			candidates = append(candidates, typedDir+name+"/")
+		case !dirsOnly:
+			candidates = append(candidates, typedDir+name)
+		}
+	}
+	return candidates
+}
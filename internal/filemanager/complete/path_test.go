@@ -0,0 +1,98 @@
+package complete
+
+import (
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type PathTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *PathTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	for _, dir := range []string{"/work/src/cmd", "/work/scripts", "/work/.git", "/home/me/Documents"} {
+		s.Require().NoError(s.fs.MkdirAll(dir, 0755))
+	}
+	for _, file := range []string{"/work/setup.sh", "/work/.env", "/work/src/main.go", "/home/me/notes.txt"} {
+		f, err := s.fs.Create(file, 0644)
+		s.Require().NoError(err)
+		s.Require().NoError(f.Close())
+	}
+	s.Require().NoError(s.fs.Symlink("/work/src", "/work/source"))
+}
+
+func (s *PathTestSuite) TestPaths() {
+	tests := []struct {
+		name     string
+		typed    string
+		dirsOnly bool
+		expected []string
+	}{
+		{
+			name:     "everything visible in the directory",
+			typed:    "",
+			expected: []string{"scripts/", "setup.sh", "source/", "src/"},
+		},
+		{
+			name:     "prefix",
+			typed:    "se",
+			expected: []string{"setup.sh"},
+		},
+		{
+			name:     "directories only",
+			typed:    "s",
+			dirsOnly: true,
+			expected: []string{"scripts/", "source/", "src/"},
+		},
+		{
+			name:     "hidden entries once the dot is typed",
+			typed:    ".",
+			expected: []string{".env", ".git/"},
+		},
+		{
+			name:     "into a subdirectory",
+			typed:    "src/",
+			expected: []string{"src/cmd/", "src/main.go"},
+		},
+		{
+			name:     "parent directory",
+			typed:    "../home/me/n",
+			expected: []string{"../home/me/notes.txt"},
+		},
+		{
+			name:     "absolute",
+			typed:    "/home/",
+			expected: []string{"/home/me/"},
+		},
+		{
+			name:     "tilde alone",
+			typed:    "~",
+			expected: []string{"~/"},
+		},
+		{
+			name:     "below the home directory",
+			typed:    "~/D",
+			expected: []string{"~/Documents/"},
+		},
+		{
+			name:     "missing directory",
+			typed:    "nope/",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.expected, Paths(s.fs, "/work", "/home/me", tt.typed, tt.dirsOnly))
+		})
+	}
+}
+
+func TestPathSuite(t *testing.T) {
+	suite.Run(t, new(PathTestSuite))
+}
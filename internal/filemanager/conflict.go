@@ -0,0 +1,81 @@
+package filemanager
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const conflictChoices = "[o]verwrite, [s]kip, [n]ew name or [c]ompare? (capital applies to all)"
+
+// conflictPrompt asks about a conflict of the running job, or compares both
+// sides once the user asked for it
+func (fm *Filemanager) conflictPrompt(c types.Conflict) string {
+	if !fm.comparing {
+		return fmt.Sprintf("%s exists. %s", c.Target, conflictChoices)
+	}
+
+	source := c.Operation.Source()
+	if c.Operation.Type() == types.Create {
+		source = ""
+	}
+	return fmt.Sprintf("existing: %s, new: %s. [o]verwrite, [s]kip or [n]ew name?",
+		fm.describeEntry(c.Target), fm.describeEntry(source))
+}
+
+// describeEntry summarises what is at path for comparing
+func (fm *Filemanager) describeEntry(path string) string {
+	if path == "" {
+		return "empty"
+	}
+
+	info, err := fm.fs.Lstat(path)
+	if err != nil {
+		return "missing"
+	}
+
+	kind := "file"
+	switch {
+	case info.IsDir():
+		kind = "directory"
+	case info.Mode()&fs.ModeSymlink != 0:
+		kind = "link"
+	}
+
+	return fmt.Sprintf("%s %s %s %s", filepath.Base(path), kind,
+		formatBytes(info.Size()), info.ModTime().Format("2006-01-02 15:04"))
+}
+
+// answerConflict handles the key pressed at the prompt for a conflict. A
+// capital letter applies the answer to every further conflict.
+func (fm *Filemanager) answerConflict(msg tea.KeyMsg) {
+	key := msg.String()
+
+	var resolution types.ConflictResolution
+	switch strings.ToLower(key) {
+	case "o":
+		resolution = types.ConflictOverwrite
+	case "s":
+		resolution = types.ConflictSkip
+	case "n":
+		resolution = types.ConflictSuffix
+	case "c":
+		fm.comparing = true
+		if c, ok := fm.job.Conflict(); ok {
+			fm.editor.SetMessage(fm.conflictPrompt(c), true)
+		}
+		return
+	case "esc", "ctrl+c":
+		fm.cancelJob()
+		return
+	default:
+		return
+	}
+
+	fm.comparing = false
+	fm.job.Resolve(resolution, key != strings.ToLower(key))
+}
@@ -0,0 +1,153 @@
+package filemanager
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/editor/buffer"
+	"github.com/gunererd/grease/internal/editor/highlight"
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/gitstatus"
+	"github.com/gunererd/grease/internal/filemanager/lscolors"
+	"github.com/gunererd/grease/internal/filemanager/picker"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// gitHighlights maps the git status of an entry to how it is drawn
+var gitHighlights = map[gitstatus.Status]eTypes.HighlightType{
+	gitstatus.Modified:   eTypes.GitModifiedHighlight,
+	gitstatus.Staged:     eTypes.GitStagedHighlight,
+	gitstatus.Untracked:  eTypes.GitUntrackedHighlight,
+	gitstatus.Ignored:    eTypes.GitIgnoredHighlight,
+	gitstatus.Conflicted: eTypes.GitConflictHighlight,
+}
+
+// gitStatusMsg carries the git status of dir once it has been read
+type gitStatusMsg struct {
+	dir      string
+	statuses map[string]gitstatus.Status
+	err      error
+}
+
+// readGitStatus asks git for the status of dir in the background, dropping
+// a read still running for the previous directory. Archives are skipped.
+func (fm *Filemanager) readGitStatus(dir string) {
+	if fm.cancelGit != nil {
+		fm.cancelGit()
+		fm.cancelGit = nil
+	}
+	fm.gitStatus = nil
+	fm.redecorate = true
+	if fm.dirManager.IsReadOnly() {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fm.cancelGit = cancel
+	fm.queueCmd(func() tea.Msg {
+		statuses, err := gitstatus.Read(ctx, dir)
+		return gitStatusMsg{dir: dir, statuses: statuses, err: err}
+	})
+}
+
+func (fm *Filemanager) updateGitStatus(msg gitStatusMsg) {
+	if msg.dir != fm.dirManager.CurrentPath() {
+		return
+	}
+
+	fm.cancelGit = nil
+	if msg.err != nil {
+		// Most directories are not in a repository at all
+		fm.logger.Println("No git status for", msg.dir+":", msg.err)
+		return
+	}
+	fm.gitStatus = msg.statuses
+	fm.redecorate = true
+}
+
+// decorationKey is what decorate last ran on besides the entry state
+// tracked by redecorate
+type decorationKey struct {
+	text  string
+	marks string
+}
+
+// colorEntries works out the colour of each entry of dir from its file
+// type. Styles are defined once per distinct sequence.
+func (fm *Filemanager) colorEntries(dir string, entries []types.Entry) {
+	fm.entryColors = make(map[string]eTypes.HighlightType, len(entries))
+	fm.redecorate = true
+	for _, e := range entries {
+		sgr := fm.colors.Color(fm.fs, filepath.Join(dir, strings.TrimSuffix(e.Name(), "/")))
+		if sgr == "" {
+			continue
+		}
+
+		t, ok := fm.colorTypes[sgr]
+		if !ok {
+			t = highlight.DefineStyle(lscolors.Style(sgr))
+			fm.colorTypes[sgr] = t
+		}
+		fm.entryColors[e.Name()] = t
+	}
+}
+
+// decorate colours the entries of the listing by file type and by their git
+// status, which wins over the former, shows which are marked to be picked
+// and their sizes once :du worked them out. It runs after every update as editing
+// moves lines around; the text itself is never touched, so reconciliation
+// does not see it. Updates that change neither the text, the marks nor what
+// is known about the entries, such as spinner ticks, keep the decorations.
+func (fm *Filemanager) decorate() {
+	key := decorationKey{text: fm.editor.Buffer().Get()}
+	if fm.picker != nil {
+		key.marks = strings.Join(fm.picker.Marks(), "\n")
+	}
+	if !fm.redecorate && key == fm.decorated {
+		return
+	}
+	fm.decorated = key
+	fm.redecorate = false
+
+	hm := fm.editor.HighlightManager()
+	for _, id := range fm.decorations {
+		hm.Remove(id)
+	}
+	fm.decorations = fm.decorations[:0]
+	fm.editor.Viewport().SetAnnotations(nil)
+	if fm.scratch != nil {
+		return
+	}
+
+	var annotations map[int]string
+	buf := fm.editor.Buffer()
+	for i := 0; i < buf.LineCount(); i++ {
+		line, err := buf.GetLine(i)
+		if err != nil || line == "" {
+			continue
+		}
+
+		if size, ok := fm.entrySizes[line]; ok {
+			if annotations == nil {
+				annotations = make(map[int]string)
+			}
+			annotations[i] = size
+		}
+
+		start, end := buffer.NewPosition(i, 0), buffer.NewPosition(i, len(line)-1)
+		if t, ok := fm.entryColors[line]; ok {
+			fm.decorations = append(fm.decorations, hm.Add(highlight.CreateColorHighlight(start, end, t)))
+		}
+		if status, ok := fm.gitStatus[line]; ok {
+			fm.decorations = append(fm.decorations, hm.Add(highlight.CreateDecorationHighlight(start, end, gitHighlights[status])))
+		}
+		if fm.picker != nil {
+			if path, _ := picker.Entry(fm.dirManager.CurrentPath(), line); fm.picker.Marked(path) {
+				fm.decorations = append(fm.decorations, hm.Add(highlight.CreateMarkedHighlight(start, end)))
+			}
+		}
+	}
+	fm.editor.Viewport().SetAnnotations(annotations)
+}
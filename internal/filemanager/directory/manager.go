@@ -1,7 +1,6 @@
 package directory
 
 import (
-	"os"
 	"sort"
 	"strings"
 
@@ -11,18 +10,20 @@ import (
 
 type Manager struct {
 	currentPath string
+	fs          types.FileSystem
 	logger      types.Logger
 }
 
-func NewDirectoryManager(initialPath string, logger types.Logger) types.DirectoryManager {
+func NewDirectoryManager(initialPath string, fs types.FileSystem, logger types.Logger) types.DirectoryManager {
 	return &Manager{
 		currentPath: initialPath,
+		fs:          fs,
 		logger:      logger,
 	}
 }
 
 func (m *Manager) ReadDirectory() ([]types.Entry, error) {
-	entries, err := os.ReadDir(m.currentPath)
+	entries, err := m.fs.ReadDir(m.currentPath)
 	if err != nil {
 		m.logger.Println("Failed to read directory:", err)
 		return nil, err
@@ -60,6 +61,10 @@ func (m *Manager) CurrentPath() string {
 	return m.currentPath
 }
 
+func (m *Manager) IsReadOnly() bool {
+	return m.fs.ReadOnly(m.currentPath)
+}
+
 func (m *Manager) GetDirectoryContent() ([]byte, error) {
 	entries, err := m.ReadDirectory()
 	if err != nil {
@@ -0,0 +1,138 @@
+// Package du adds up the size of directory trees
+package du
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// key identifies a directory as it was when its total was cached. A
+// directory whose entries change gets a new modification time, changes
+// further down the tree do not show up in it though.
+type key struct {
+	id    fileID
+	mtime time.Time
+}
+
+// Calculator walks each tree in a goroutine of its own, at most workers at
+// a time. Totals of every directory on the way are cached, so walking a
+// parent again reuses those of its children.
+type Calculator struct {
+	fs      types.FileSystem
+	workers int
+	mu      sync.Mutex
+	cache   map[key]int64
+}
+
+func New(fs types.FileSystem, workers int) types.SizeCalculator {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Calculator{
+		fs:      fs,
+		workers: workers,
+		cache:   make(map[key]int64),
+	}
+}
+
+func (c *Calculator) Sizes(ctx context.Context, dir string, names []string, fresh bool) <-chan types.SizeResult {
+	results := make(chan types.SizeResult)
+	slots := make(chan struct{}, c.workers)
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			size, err := c.total(ctx, filepath.Join(dir, name), fresh)
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case results <- types.SizeResult{Name: name, Size: size, Err: err}:
+			case <-ctx.Done():
+			}
+		}(name)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// total returns the apparent size of the files below path, not following
+// symbolic links. Subdirectories that cannot be read count as empty.
+func (c *Calculator) total(ctx context.Context, path string, fresh bool) (int64, error) {
+	info, err := c.fs.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	return c.size(ctx, path, info, fresh)
+}
+
+func (c *Calculator) size(ctx context.Context, path string, info fs.FileInfo, fresh bool) (int64, error) {
+	if !info.IsDir() {
+		return info.Size(), nil
+	}
+
+	k := key{id: identify(path, info), mtime: info.ModTime()}
+	if !fresh {
+		if total, ok := c.lookup(k); ok {
+			return total, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	entries, err := c.fs.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := c.fs.Lstat(child)
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+
+		size, err := c.size(ctx, child, childInfo, fresh)
+		if err != nil && ctx.Err() != nil {
+			return 0, err
+		}
+		total += size
+	}
+
+	c.store(k, total)
+	return total, nil
+}
+
+func (c *Calculator) lookup(k key) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total, ok := c.cache[k]
+	return total, ok
+}
+
+func (c *Calculator) store(k key, total int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[k] = total
+}
@@ -0,0 +1,90 @@
+package du
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type DuTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *DuTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/work/a/sub", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/work/empty", 0755))
+	s.writeFile("/work/a/x", 10)
+	s.writeFile("/work/a/sub/y", 5)
+	s.writeFile("/work/f.txt", 3)
+}
+
+func (s *DuTestSuite) writeFile(path string, size int) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(strings.Repeat("x", size)))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+// sizes collects the results of a walk by name
+func (s *DuTestSuite) sizes(c types.SizeCalculator, fresh bool, names ...string) map[string]int64 {
+	sizes := make(map[string]int64)
+	for result := range c.Sizes(context.Background(), "/work", names, fresh) {
+		s.Require().NoError(result.Err)
+		sizes[result.Name] = result.Size
+	}
+	return sizes
+}
+
+func (s *DuTestSuite) TestSizes() {
+	c := New(s.fs, 2)
+	s.Equal(map[string]int64{"a": 15, "empty": 0, "f.txt": 3}, s.sizes(c, false, "a", "empty", "f.txt"))
+}
+
+func (s *DuTestSuite) TestCache() {
+	c := New(s.fs, 1)
+	s.Equal(int64(15), s.sizes(c, false, "a")["a"])
+
+	// Growing a file leaves the directory as it was
+	s.writeFile("/work/a/x", 20)
+	s.Equal(int64(15), s.sizes(c, false, "a")["a"])
+	s.Equal(int64(25), s.sizes(c, true, "a")["a"])
+
+	// Only the changed subdirectory is walked again
+	s.writeFile("/work/a/sub/z", 100)
+	s.Require().NoError(s.fs.Chtimes("/work/a/sub", time.Now(), time.Now().Add(time.Minute)))
+	s.Require().NoError(s.fs.Chtimes("/work/a", time.Now(), time.Now().Add(time.Minute)))
+	s.Equal(int64(125), s.sizes(c, false, "a")["a"])
+}
+
+func (s *DuTestSuite) TestMissing() {
+	results := New(s.fs, 1).Sizes(context.Background(), "/work", []string{"gone"}, false)
+	result := <-results
+	s.Equal("gone", result.Name)
+	s.Error(result.Err)
+
+	_, open := <-results
+	s.False(open)
+}
+
+func (s *DuTestSuite) TestCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var results []types.SizeResult
+	for result := range New(s.fs, 1).Sizes(ctx, "/work", []string{"a", "empty"}, false) {
+		results = append(results, result)
+	}
+	s.Empty(results)
+}
+
+func TestDuSuite(t *testing.T) {
+	suite.Run(t, new(DuTestSuite))
+}
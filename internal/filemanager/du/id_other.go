@@ -0,0 +1,14 @@
+//go:build !unix
+
+package du
+
+import "io/fs"
+
+// fileID is the path of a file, as there are no inodes to tell files apart
+type fileID struct {
+	path string
+}
+
+func identify(path string, info fs.FileInfo) fileID {
+	return fileID{path: path}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package du
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileID is the device and inode of a file, or its path where the file
+// system has no inodes such as inside an archive
+type fileID struct {
+	dev, ino uint64
+	path     string
+}
+
+func identify(path string, info fs.FileInfo) fileID {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fileID{dev: uint64(st.Dev), ino: st.Ino}
+	}
+	return fileID{path: path}
+}
@@ -0,0 +1,98 @@
+package filemanager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+)
+
+// editorFinishedMsg is sent when an external editor exits
+type editorFinishedMsg struct {
+	err error
+}
+
+// openInEditor suspends the UI and opens path in $VISUAL or $EDITOR, on the
+// given line if it is positive
+func openInEditor(path string, line int) tea.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	if line > 0 {
+		args = append(args, fmt.Sprintf("+%d", line))
+	}
+	args = append(args, path)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	})
+}
+
+// shellFinishedMsg carries the output of a command started with RunShell
+type shellFinishedMsg struct {
+	command string
+	output  string
+	err     error
+}
+
+// RunShell runs command with sh in the current directory in the background.
+// Once it exits its output is shown and the directory is reloaded, so that
+// what it changed shows up.
+func (fm *Filemanager) RunShell(command string) {
+	if fm.dirManager.IsReadOnly() {
+		fm.editor.SetMessage("Shell commands cannot run inside an archive", true)
+		return
+	}
+
+	dir := fm.dirManager.CurrentPath()
+	fm.editor.SetMessage("Running "+command+"…", false)
+	fm.queueCmd(func() tea.Msg {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		return shellFinishedMsg{command: command, output: string(output), err: err}
+	})
+}
+
+// finishShell shows the output of a command in the status line, or in a
+// scratch when it has more than one line
+func (fm *Filemanager) finishShell(msg shellFinishedMsg) {
+	switch {
+	case fm.scratch != nil:
+	case fm.job != nil || fm.unsaved():
+		// Reloading would throw away edits that are not saved yet
+		fm.logger.Println("Not reloading after", msg.command+": unsaved changes")
+	default:
+		if err := fm.reload(); err != nil {
+			fm.logger.Println("Failed to reload directory:", err)
+		}
+	}
+
+	fm.editor.SetMessage("", false)
+	lines := strings.Split(strings.TrimRight(msg.output, "\n"), "\n")
+	if len(lines) > 1 {
+		if err := fm.OpenScratch(scratch.NewText("!"+msg.command, lines)); err != nil {
+			fm.logger.Println("Failed to show output:", err)
+		}
+		lines = nil
+	}
+
+	switch {
+	case msg.err != nil:
+		fm.editor.SetMessage(fmt.Sprintf("%s: %v", msg.command, msg.err), true)
+	case len(lines) == 1 && lines[0] != "":
+		fm.editor.SetMessage(lines[0], false)
+	case lines != nil:
+		fm.editor.SetMessage(msg.command+" finished", false)
+	}
+}
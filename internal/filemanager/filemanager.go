@@ -1,6 +1,7 @@
 package filemanager
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,41 +9,179 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/command"
+	"github.com/gunererd/grease/internal/filemanager/complete"
+	"github.com/gunererd/grease/internal/filemanager/finder"
+	"github.com/gunererd/grease/internal/filemanager/gitstatus"
 	"github.com/gunererd/grease/internal/filemanager/handler"
 	"github.com/gunererd/grease/internal/filemanager/hook"
+	"github.com/gunererd/grease/internal/filemanager/operation"
 	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
 )
 
 type Filemanager struct {
-	dirManager types.DirectoryManager
-	opManager  types.OperationManager
-	view       types.View
-	handler    types.Handler
-	editor     eTypes.Editor
-	logger     types.Logger
+	fs            types.FileSystem
+	dirManager    types.DirectoryManager
+	opManager     types.OperationManager
+	bookmarks     types.BookmarkManager
+	history       types.NavigationHistory
+	finder        types.Finder
+	frecency      types.Frecency // nil when visits are not recorded
+	view          types.View
+	handler       types.Handler
+	editor        eTypes.Editor
+	opHook        *hook.FileOperationHook
+	listed        []string // listing as last saved, the buffer is compared to it
+	scratch       types.Scratch
+	scratchHook   eTypes.Hook
+	results       []types.OperationResult
+	job           *operation.Job
+	asking        bool // waiting for an answer on how to go on after a failure
+	comparing     bool // showing both sides of a conflict
+	colors        types.EntryColors
+	picker        types.Picker                    // nil unless grease runs as a file chooser
+	colorTypes    map[string]eTypes.HighlightType // defined style per SGR sequence
+	entryColors   map[string]eTypes.HighlightType
+	gitStatus     map[string]gitstatus.Status
+	cancelGit     context.CancelFunc
+	sizes         types.SizeCalculator
+	entrySizes    map[string]string // shown next to entries, nil when hidden
+	cancelSizes   context.CancelFunc
+	searcher      types.Searcher
+	searchID      int // of the latest Grep, earlier results are dropped
+	cancelGrep    context.CancelFunc
+	compareID     int // of the latest comparison, earlier results are dropped
+	cancelCompare context.CancelFunc
+	decorations   []int // highlight IDs added by decorate
+	decorated     decorationKey
+	redecorate    bool // entry colours, git status, sizes or the scratch changed since decorate ran
+	cmds          []tea.Cmd
+	logger        types.Logger
 }
 
 func New(
+	fs types.FileSystem,
 	dirManager types.DirectoryManager,
 	opManager types.OperationManager,
+	bookmarks types.BookmarkManager,
+	history types.NavigationHistory,
+	finder types.Finder,
+	frecency types.Frecency,
 	view types.View,
+	colors types.EntryColors,
+	sizes types.SizeCalculator,
+	searcher types.Searcher,
+	picker types.Picker,
 	editor eTypes.Editor,
 	logger types.Logger,
 ) types.FileManager {
 	fm := &Filemanager{
+		fs:         fs,
 		dirManager: dirManager,
 		opManager:  opManager,
+		bookmarks:  bookmarks,
+		history:    history,
+		finder:     finder,
+		frecency:   frecency,
 		view:       view,
+		colors:     colors,
+		sizes:      sizes,
+		searcher:   searcher,
+		picker:     picker,
+		colorTypes: make(map[string]eTypes.HighlightType),
 		editor:     editor,
 		logger:     logger,
 	}
 
-	fm.handler = handler.New(dirManager, editor, fm.LoadDirectory, logger)
-	editor.AddHook(hook.NewFileOperationHook(dirManager, opManager))
+	fm.handler = handler.New(dirManager, bookmarks, history, finder, picker, editor, fm.LoadDirectory, fm.Scratch, logger)
+	fm.opHook = hook.NewFileOperationHook(dirManager, fm.save, logger)
+	editor.AddHook(fm.opHook)
+
+	editor.RegisterCommand("bookmarks", func(args string) eTypes.Command {
+		return command.NewBookmarksCommand(fm)
+	})
+	for _, name := range []string{"s", "substitute"} {
+		editor.RegisterCommand(name, func(args string) eTypes.Command {
+			return command.NewSubstituteCommand(fm, args, false)
+		})
+	}
+	editor.RegisterCommand("%s", func(args string) eTypes.Command {
+		return command.NewSubstituteCommand(fm, args, true)
+	})
+	editor.RegisterCommand("results", func(args string) eTypes.Command {
+		return command.NewResultsCommand(fm)
+	})
+	editor.RegisterCommand("oplog", func(args string) eTypes.Command {
+		return command.NewOplogCommand(fm, args)
+	})
+	editor.RegisterCommand("export-plan", func(args string) eTypes.Command {
+		return command.NewExportPlanCommand(fm, args)
+	})
+	editor.RegisterCommand("!", func(args string) eTypes.Command {
+		return command.NewShellCommand(fm, args)
+	})
+	editor.RegisterCommand("cd", func(args string) eTypes.Command {
+		return command.NewCdCommand(fm, args)
+	})
+	for _, name := range []string{"e", "edit"} {
+		editor.RegisterCommand(name, func(args string) eTypes.Command {
+			return command.NewEditCommand(fm, args)
+		})
+	}
+	editor.RegisterCommand("pwd", func(args string) eTypes.Command {
+		return command.NewPwdCommand(fm)
+	})
+	editor.RegisterCommand("refresh", func(args string) eTypes.Command {
+		return command.NewRefreshCommand(fm)
+	})
+	editor.RegisterCommand("du", func(args string) eTypes.Command {
+		return command.NewDuCommand(fm, args)
+	})
+	editor.RegisterCommand("pack", func(args string) eTypes.Command {
+		return command.NewPackCommand(fm, args)
+	})
+	editor.RegisterCommand("extract", func(args string) eTypes.Command {
+		return command.NewExtractCommand(fm, args)
+	})
+	editor.RegisterCommand("grep", func(args string) eTypes.Command {
+		return command.NewGrepCommand(fm, args)
+	})
+	editor.RegisterCommand("compare", func(args string) eTypes.Command {
+		return command.NewCompareCommand(fm, args)
+	})
+	editor.RegisterCommand("z", func(args string) eTypes.Command {
+		return command.NewZCommand(fm, strings.Fields(args))
+	})
+	editor.RegisterCommand("mkdir", func(args string) eTypes.Command {
+		return command.NewMkdirCommand(fm, args)
+	})
+	editor.RegisterCommand("touch", func(args string) eTypes.Command {
+		return command.NewTouchCommand(fm, args)
+	})
+	for _, name := range []string{"cd", "mkdir", "compare", "extract"} {
+		editor.RegisterCompletion(name, fm.completePath(true))
+	}
+	for _, name := range []string{"e", "edit", "touch", "pack"} {
+		editor.RegisterCompletion(name, fm.completePath(false))
+	}
+
+	if picker != nil {
+		editor.RegisterCommand("mark", func(args string) eTypes.Command {
+			return command.NewMarkCommand(fm)
+		})
+		editor.RegisterCommand("pick", func(args string) eTypes.Command {
+			return command.NewPickCommand(fm)
+		})
+	}
 
 	return fm
 }
 
+func (fm *Filemanager) FileSystem() types.FileSystem {
+	return fm.fs
+}
+
 func (fm *Filemanager) DirectoryManager() types.DirectoryManager {
 	return fm.dirManager
 }
@@ -51,32 +190,105 @@ func (fm *Filemanager) OperationManager() types.OperationManager {
 	return fm.opManager
 }
 
+func (fm *Filemanager) BookmarkManager() types.BookmarkManager {
+	return fm.bookmarks
+}
+
 func (fm *Filemanager) Editor() eTypes.Editor {
 	return fm.editor
 }
 
 // Implement tea.Model interface
 func (fm *Filemanager) Init() tea.Cmd {
-	return nil
+	// Work started while loading the first directory
+	cmds := fm.cmds
+	fm.cmds = nil
+	return tea.Batch(cmds...)
 }
 
 func (fm *Filemanager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmd := fm.update(msg)
+	fm.decorate()
+
+	// Hooks run inside the editor and cannot return commands themselves
+	cmds := append(fm.cmds, cmd)
+	fm.cmds = nil
+	return fm, tea.Batch(cmds...)
+}
+
+func (fm *Filemanager) update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case finder.SelectedMsg:
+		return fm.openSelection(msg)
+	case editorFinishedMsg:
+		if msg.err != nil {
+			fm.logger.Println("Editor exited with error:", msg.err)
+		}
+		return nil
+	case jobTickMsg:
+		return fm.updateJob(msg)
+	case gitStatusMsg:
+		fm.updateGitStatus(msg)
+		return nil
+	case searchFinishedMsg:
+		fm.finishSearch(msg)
+		return nil
+	case compareFinishedMsg:
+		fm.finishCompare(msg)
+		return nil
+	case shellFinishedMsg:
+		fm.finishShell(msg)
+		return nil
+	case sizeMsg:
+		return fm.updateSize(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if fm.asking {
+			fm.answerFailure(msg)
+			return nil
+		}
+		if fm.job != nil {
+			if _, ok := fm.job.Conflict(); ok {
+				fm.answerConflict(msg)
+				return nil
+			}
+			if msg.String() == "ctrl+c" {
+				fm.cancelJob()
+				return nil
+			}
+		}
+	}
+
+	if fm.finder.Active() {
+		if _, ok := msg.(tea.WindowSizeMsg); ok {
+			fm.editor.Update(msg)
+		}
+		return fm.finder.Update(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Let handler process the input first
-		if cmd, err := fm.handler.Handle(msg); err != nil {
-			return fm, cmd
+		handled, cmd, err := fm.handler.Handle(msg)
+		if err != nil {
+			fm.logger.Println("Failed to handle key:", err)
 		}
-		// If handler didn't handle it, pass to editor
-		if _, cmd := fm.editor.Update(msg); cmd != nil {
-			return fm, cmd
+		if handled {
+			return cmd
 		}
+		// If handler didn't handle it, pass to editor
+		_, cmd = fm.editor.Update(msg)
+		return cmd
 	default:
-		if _, cmd := fm.editor.Update(msg); cmd != nil {
-			return fm, cmd
-		}
+		_, cmd := fm.editor.Update(msg)
+		return cmd
 	}
-	return fm, nil
+}
+
+// queueCmd schedules cmd to be returned from the current Update
+func (fm *Filemanager) queueCmd(cmd tea.Cmd) {
+	fm.cmds = append(fm.cmds, cmd)
 }
 
 func (fm *Filemanager) View() string {
@@ -84,10 +296,27 @@ func (fm *Filemanager) View() string {
 }
 
 func (fm *Filemanager) LoadDirectory(path string) error {
-	resolvedPath, err := resolvePath(path)
+	resolvedPath, err := resolvePath(fm.fs, path)
 	if err != nil {
 		return fmt.Errorf("failed to resolve path: %w", err)
 	}
+	if fm.picker != nil && !fm.picker.Within(resolvedPath) {
+		fm.editor.SetMessage("Cannot leave the root of the picker", true)
+		return fmt.Errorf("%s is outside of the picker root", resolvedPath)
+	}
+
+	// Edits of the listing being left stay queued until the next save
+	if err := fm.QueueEdits(); err != nil {
+		fm.editor.SetMessage(err.Error(), true)
+		return err
+	}
+
+	previousPath := fm.dirManager.CurrentPath()
+	if fm.scratch == nil && previousPath != "" {
+		if entry, ok := fm.entryUnderCursor(); ok {
+			fm.history.SetCursor(previousPath, entry)
+		}
+	}
 
 	if err := fm.dirManager.ChangeDirectory(resolvedPath); err != nil {
 		return fmt.Errorf("failed to change directory: %w", err)
@@ -98,19 +327,269 @@ func (fm *Filemanager) LoadDirectory(path string) error {
 		return err
 	}
 
+	fm.closeScratch()
+	fm.colorEntries(resolvedPath, entries)
+	if resolvedPath != previousPath {
+		fm.cancelSizeWalk()
+		fm.cancelComparison()
+		fm.cancelSearch()
+		fm.entrySizes = nil
+		fm.redecorate = true
+	}
+
 	var sb strings.Builder
+	fm.listed = make([]string, len(entries))
 	for i, entry := range entries {
+		fm.listed[i] = entry.Name()
 		sb.WriteString(entry.Name())
 		if i < len(entries)-1 {
 			sb.WriteString("\n")
 		}
 	}
 
-	return fm.editor.Buffer().LoadFromReader(strings.NewReader(sb.String()))
+	if err := fm.editor.Buffer().LoadFromReader(strings.NewReader(sb.String())); err != nil {
+		return err
+	}
+
+	fm.history.Push(resolvedPath)
+	if resolvedPath != previousPath {
+		fm.recordVisit(resolvedPath)
+	}
+	fm.restoreCursor(resolvedPath, previousPath)
+	fm.readGitStatus(resolvedPath)
+	return nil
+}
+
+// recordVisit ranks dir up for :z. Paths inside archives are left out, they
+// are gone once grease quits.
+func (fm *Filemanager) recordVisit(dir string) {
+	if fm.frecency == nil || fm.fs.ReadOnly(dir) {
+		return
+	}
+	if err := fm.frecency.Add(dir); err != nil {
+		fm.logger.Println("Failed to record visit:", err)
+	}
+}
+
+// Jump changes to the best ranked directory matching keywords other than the
+// current one. Without keywords all ranked directories are offered in the
+// finder instead.
+func (fm *Filemanager) Jump(keywords ...string) error {
+	if fm.frecency == nil {
+		return fmt.Errorf("directory ranking is turned off")
+	}
+
+	dirs, err := fm.frecency.Query(keywords...)
+	if err != nil {
+		return fmt.Errorf("failed to query directory ranking: %w", err)
+	}
+
+	current := fm.dirManager.CurrentPath()
+	candidates := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir != current && (fm.picker == nil || fm.picker.Within(dir)) {
+			candidates = append(candidates, dir)
+		}
+	}
+	if len(candidates) == 0 {
+		if len(keywords) == 0 {
+			return fmt.Errorf("no directories ranked yet")
+		}
+		return fmt.Errorf("no ranked directory matches %q", strings.Join(keywords, " "))
+	}
+
+	if len(keywords) > 0 {
+		return fm.LoadDirectory(candidates[0])
+	}
+
+	for i, dir := range candidates {
+		candidates[i] = strings.TrimSuffix(dir, "/") + "/"
+	}
+	fm.queueCmd(fm.finder.OpenPaths(candidates))
+	return nil
+}
+
+// reload reads the current directory again, keeping the cursor on its entry
+func (fm *Filemanager) reload() error {
+	return fm.LoadDirectory(fm.dirManager.CurrentPath())
+}
+
+// restoreCursor puts the cursor back on the entry it was on when dir was
+// last left. When going up into a directory for the first time it lands on
+// the child we came from.
+func (fm *Filemanager) restoreCursor(dir, previousDir string) {
+	entry, ok := fm.history.Cursor(dir)
+	if !ok && previousDir != "" && filepath.Dir(previousDir) == dir {
+		entry, ok = filepath.Base(previousDir)+"/", true
+	}
+
+	if ok {
+		fm.focusEntry(entry)
+		return
+	}
+	fm.moveCursorToLine(0)
+}
+
+// focusEntry moves the cursor to the line with the given entry name, or to
+// the first line if there is no such entry
+func (fm *Filemanager) focusEntry(name string) {
+	buf := fm.editor.Buffer()
+	for i := 0; i < buf.LineCount(); i++ {
+		if line, err := buf.GetLine(i); err == nil && line == name {
+			fm.moveCursorToLine(i)
+			return
+		}
+	}
+	fm.moveCursorToLine(0)
+}
+
+func (fm *Filemanager) entryUnderCursor() (string, bool) {
+	cursor, err := fm.editor.Buffer().GetPrimaryCursor()
+	if err != nil {
+		return "", false
+	}
+
+	line, err := fm.editor.Buffer().GetLine(cursor.GetPosition().Line())
+	if err != nil || line == "" {
+		return "", false
+	}
+	return line, true
+}
+
+// Reveal loads the directory containing path with the cursor on its entry
+func (fm *Filemanager) Reveal(path string) error {
+	name := filepath.Base(path)
+	if strings.HasSuffix(path, "/") {
+		name += "/"
+	}
+
+	if err := fm.LoadDirectory(filepath.Dir(filepath.Clean(path))); err != nil {
+		return err
+	}
+	fm.focusEntry(name)
+	return nil
+}
+
+// Open loads a directory or archive into the listing and opens a file in
+// $EDITOR
+func (fm *Filemanager) Open(path string) error {
+	info, err := fm.fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || vfs.IsArchive(path) {
+		return fm.LoadDirectory(path)
+	}
+	return fm.edit(path, 0)
+}
+
+// edit opens the file at path in $EDITOR, on line if it is positive
+func (fm *Filemanager) edit(path string, line int) error {
+	if fm.fs.ReadOnly(path) {
+		return fmt.Errorf("files inside archives cannot be edited")
+	}
+
+	fm.queueCmd(openInEditor(path, line))
+	return nil
+}
+
+func (fm *Filemanager) openSelection(msg finder.SelectedMsg) tea.Cmd {
+	if strings.HasSuffix(msg.Path, "/") {
+		if err := fm.LoadDirectory(msg.Path); err != nil {
+			fm.logger.Println("Failed to open directory:", err)
+		}
+		return nil
+	}
+
+	if msg.Open {
+		if err := fm.edit(msg.Path, 0); err != nil {
+			fm.editor.SetMessage(err.Error(), true)
+		}
+		return nil
+	}
+
+	if err := fm.Reveal(msg.Path); err != nil {
+		fm.logger.Println("Failed to reveal file:", err)
+	}
+	return nil
+}
+
+// OpenScratch replaces the directory listing with the scratch's lines. File
+// operations are not tracked until the next LoadDirectory, edits of the
+// listing stay queued until the next save.
+func (fm *Filemanager) OpenScratch(scratch types.Scratch) error {
+	lines, err := scratch.Lines()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", scratch.Name(), err)
+	}
+	if err := fm.QueueEdits(); err != nil {
+		return err
+	}
+
+	fm.closeScratch()
+	fm.editor.RemoveHook(fm.opHook)
+	fm.scratch = scratch
+	fm.redecorate = true
+	fm.scratchHook = hook.NewScratchHook(scratch, fm.logger)
+	fm.editor.AddHook(fm.scratchHook)
+
+	if err := fm.editor.Buffer().LoadFromReader(strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		return err
+	}
+
+	fm.moveCursorToLine(0)
+	return nil
+}
+
+// refreshScratch reads the lines of the open scratch again, such as after
+// it ran operations, keeping the cursor on its line
+func (fm *Filemanager) refreshScratch() {
+	line := 0
+	if cursor, err := fm.editor.Buffer().GetPrimaryCursor(); err == nil {
+		line = cursor.GetPosition().Line()
+	}
+	if err := fm.OpenScratch(fm.scratch); err != nil {
+		fm.logger.Println("Failed to refresh scratch:", err)
+		return
+	}
+	if last := fm.editor.Buffer().LineCount() - 1; line > last {
+		line = last
+	}
+	fm.moveCursorToLine(line)
+}
+
+// Scratch returns the open scratch, or nil when a directory is shown
+func (fm *Filemanager) Scratch() types.Scratch {
+	return fm.scratch
+}
+
+func (fm *Filemanager) closeScratch() {
+	if fm.scratch == nil {
+		return
+	}
+
+	fm.editor.RemoveHook(fm.scratchHook)
+	fm.editor.AddHook(fm.opHook)
+	fm.scratch = nil
+	fm.scratchHook = nil
+	fm.redecorate = true
+}
+
+func (fm *Filemanager) moveCursorToLine(line int) {
+	fm.editor.Buffer().ClearCursors()
+	cursor, err := fm.editor.Buffer().GetPrimaryCursor()
+	if err != nil {
+		return
+	}
+
+	if err := fm.editor.Buffer().MoveCursor(cursor.ID(), line, 0); err != nil {
+		fm.logger.Println("Failed to move cursor:", err)
+	}
+	fm.editor.HandleCursorMovement()
 }
 
 // resolvePath sanitizes and resolves the given path
-func resolvePath(path string) (string, error) {
+func resolvePath(fs types.FileSystem, path string) (string, error) {
 	if path == "" {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -119,13 +598,37 @@ func resolvePath(path string) (string, error) {
 		return pwd, nil
 	}
 
-	// Expand home directory if needed
-	if strings.HasPrefix(path, "~") {
+	absPath, err := expandPath("", path)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify directory exists and is accessible
+	info, err := fs.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to access path: %w", err)
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("path is not a directory: %s", absPath)
+	}
+
+	return absPath, nil
+}
+
+// expandPath expands a leading "~" to the home directory and makes path
+// absolute against dir, or the working directory when dir is empty
+func expandPath(dir, path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
 			return "", fmt.Errorf("failed to get home directory: %w", err)
 		}
-		path = strings.Replace(path, "~", homeDir, 1)
+		path = homeDir + path[1:]
+	}
+
+	if dir != "" && !filepath.IsAbs(path) {
+		return filepath.Join(dir, path), nil
 	}
 
 	// Clean and make absolute
@@ -133,18 +636,36 @@ func resolvePath(path string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
 	}
+	return absPath, nil
+}
 
-	// Verify directory exists and is accessible
-	info, err := os.Stat(absPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to access path: %w", err)
+// completePath completes command arguments to the paths below the current
+// directory, or only the directories among them
+func (fm *Filemanager) completePath(dirsOnly bool) eTypes.Completer {
+	return func(typed string) []string {
+		home, _ := os.UserHomeDir()
+		return complete.Paths(fm.fs, fm.dirManager.CurrentPath(), home, typed, dirsOnly)
 	}
+}
 
-	if !info.IsDir() {
-		return "", fmt.Errorf("path is not a directory: %s", absPath)
-	}
+// ExpandPath returns path absolute against the current directory, with a
+// leading "~" expanded to the home directory
+func (fm *Filemanager) ExpandPath(path string) (string, error) {
+	return expandPath(fm.dirManager.CurrentPath(), path)
+}
 
-	return absPath, nil
+// Picker returns what is picked when grease runs as a file chooser, or nil
+func (fm *Filemanager) Picker() types.Picker {
+	return fm.picker
+}
+
+// FinishPicking confirms the marked entries and quits
+func (fm *Filemanager) FinishPicking() {
+	if fm.picker == nil {
+		return
+	}
+	fm.picker.Confirm()
+	fm.queueCmd(tea.Quit)
 }
 
 func (fm *Filemanager) Logger() types.Logger {
@@ -0,0 +1,344 @@
+package finder
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const tickInterval = 100 * time.Millisecond
+
+var (
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("#303030")).
+			Foreground(lipgloss.Color("#ffffff"))
+	matchStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#87afff"))
+	promptStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#af5f00"))
+	countStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#808080"))
+)
+
+// SelectedMsg is sent when an entry is chosen in the finder. Path is
+// absolute, directories keep their trailing slash. Open is set when the
+// file should be opened instead of revealed in the listing.
+type SelectedMsg struct {
+	Path string
+	Open bool
+}
+
+type tickMsg struct {
+	generation int
+}
+
+type match struct {
+	index int
+	score int
+}
+
+// Finder is a fuzzy finder overlay over all paths below a root directory
+type Finder struct {
+	active     bool
+	root       string
+	query      string
+	lastQuery  string
+	walker     *walker
+	walkDone   bool
+	generation int
+	candidates []string
+	runes      [][]rune
+	matches    []match
+	selected   int
+	fs         types.FileSystem
+	ignore     types.IgnoreMatcher
+	logger     types.Logger
+}
+
+func New(fs types.FileSystem, ignore types.IgnoreMatcher, logger types.Logger) types.Finder {
+	return &Finder{
+		fs:     fs,
+		ignore: ignore,
+		logger: logger,
+	}
+}
+
+// Open starts walking root and shows the overlay
+func (f *Finder) Open(root string) tea.Cmd {
+	f.Close()
+
+	f.active = true
+	f.root = root
+	f.generation++
+	f.walker = newWalker(f.fs, root, f.ignore, f.logger)
+
+	return f.tick()
+}
+
+// OpenPaths shows the overlay over a fixed list of absolute paths instead
+// of walking a directory, keeping their order until a query is typed
+func (f *Finder) OpenPaths(paths []string) tea.Cmd {
+	f.Close()
+
+	f.active = true
+	f.root = ""
+	f.generation++
+	f.walkDone = true
+	f.candidates = paths
+	f.runes = make([][]rune, len(paths))
+	f.matches = make([]match, len(paths))
+	for i, path := range paths {
+		f.runes[i] = []rune(path)
+		f.matches[i] = match{index: i}
+	}
+	return nil
+}
+
+// Close hides the overlay and stops the walk
+func (f *Finder) Close() {
+	if f.walker != nil {
+		f.walker.stop()
+	}
+
+	f.active = false
+	f.walker = nil
+	f.walkDone = false
+	f.query = ""
+	f.lastQuery = ""
+	f.candidates = nil
+	f.runes = nil
+	f.matches = nil
+	f.selected = 0
+}
+
+func (f *Finder) Active() bool {
+	return f.active
+}
+
+func (f *Finder) Update(msg tea.Msg) tea.Cmd {
+	if !f.active {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case tickMsg:
+		if msg.generation != f.generation {
+			return nil
+		}
+		f.collect()
+		if f.walkDone {
+			return nil
+		}
+		return f.tick()
+
+	case tea.KeyMsg:
+		return f.handleKey(msg)
+	}
+
+	return nil
+}
+
+func (f *Finder) handleKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		f.Close()
+	case "enter":
+		return f.choose(false)
+	case "ctrl+e":
+		return f.choose(true)
+	case "up", "ctrl+p", "ctrl+k":
+		if f.selected > 0 {
+			f.selected--
+		}
+	case "down", "ctrl+n", "ctrl+j":
+		if f.selected < len(f.matches)-1 {
+			f.selected++
+		}
+	case "backspace":
+		if f.query != "" {
+			runes := []rune(f.query)
+			f.query = string(runes[:len(runes)-1])
+			f.filter()
+		}
+	case "ctrl+u":
+		f.query = ""
+		f.filter()
+	case " ":
+		f.query += " "
+		f.filter()
+	default:
+		if msg.Type == tea.KeyRunes {
+			f.query += string(msg.Runes)
+			f.filter()
+		}
+	}
+	return nil
+}
+
+func (f *Finder) choose(open bool) tea.Cmd {
+	if len(f.matches) == 0 {
+		return nil
+	}
+
+	rel := f.candidates[f.matches[f.selected].index]
+	path := filepath.Join(f.root, rel)
+	if strings.HasSuffix(rel, "/") {
+		path += "/"
+	}
+	f.Close()
+
+	return func() tea.Msg {
+		return SelectedMsg{Path: path, Open: open}
+	}
+}
+
+func (f *Finder) tick() tea.Cmd {
+	generation := f.generation
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg {
+		return tickMsg{generation: generation}
+	})
+}
+
+// collect takes the paths walked since the last tick and scores only those
+// against the current query
+func (f *Finder) collect() {
+	paths, done := f.walker.since(len(f.candidates))
+	f.walkDone = done
+	if len(paths) == 0 {
+		return
+	}
+
+	offset := len(f.candidates)
+	f.candidates = append(f.candidates, paths...)
+	pattern, caseSensitive := []rune(f.query), hasUpper(f.query)
+	for i, path := range paths {
+		runes := []rune(path)
+		f.runes = append(f.runes, runes)
+		if s, ok := score(pattern, runes, caseSensitive, nil); ok {
+			f.matches = append(f.matches, match{index: offset + i, score: s})
+		}
+	}
+	f.sort()
+}
+
+// filter rescores after the query changed. When the query only grew the
+// previous matches are a superset of the new ones, so only those are
+// rescored.
+func (f *Finder) filter() {
+	var indexes []int
+	if f.lastQuery != "" && strings.HasPrefix(f.query, f.lastQuery) {
+		indexes = make([]int, len(f.matches))
+		for i, m := range f.matches {
+			indexes[i] = m.index
+		}
+	} else {
+		indexes = make([]int, len(f.candidates))
+		for i := range f.candidates {
+			indexes[i] = i
+		}
+	}
+
+	pattern, caseSensitive := []rune(f.query), hasUpper(f.query)
+	matches := make([]match, 0, len(indexes))
+	for _, i := range indexes {
+		if s, ok := score(pattern, f.runes[i], caseSensitive, nil); ok {
+			matches = append(matches, match{index: i, score: s})
+		}
+	}
+
+	f.matches = matches
+	f.lastQuery = f.query
+	f.selected = 0
+	f.sort()
+}
+
+func (f *Finder) sort() {
+	// Without a query everything scores the same, keep the walk order
+	if f.query == "" {
+		return
+	}
+
+	sort.SliceStable(f.matches, func(i, j int) bool {
+		a, b := f.matches[i], f.matches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return len(f.candidates[a.index]) < len(f.candidates[b.index])
+	})
+}
+
+// View renders the results above a prompt line, keeping the selection
+// visible
+func (f *Finder) View(width, height int) string {
+	rows := height - 1
+	if rows < 1 {
+		rows = 1
+	}
+
+	first := 0
+	if f.selected >= rows {
+		first = f.selected - rows + 1
+	}
+
+	lines := make([]string, 0, height)
+	for i := first; i < len(f.matches) && len(lines) < rows; i++ {
+		lines = append(lines, f.renderMatch(f.matches[i], i == f.selected, width))
+	}
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+
+	status := fmt.Sprintf("%d/%d", len(f.matches), len(f.candidates))
+	if !f.walkDone {
+		status += "…"
+	}
+	prompt := promptStyle.Render("> ") + f.query
+	padding := width - lipgloss.Width(prompt) - lipgloss.Width(status)
+	if padding < 1 {
+		padding = 1
+	}
+	lines = append(lines, prompt+strings.Repeat(" ", padding)+countStyle.Render(status))
+
+	return strings.Join(lines, "\n")
+}
+
+func (f *Finder) renderMatch(m match, selected bool, width int) string {
+	_, positions, _ := Score(f.query, f.candidates[m.index])
+
+	runes := f.runes[m.index]
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+
+	highlighted := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		highlighted[p] = true
+	}
+
+	var sb strings.Builder
+	for i, r := range runes {
+		if highlighted[i] {
+			sb.WriteString(matchStyle.Render(string(r)))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+
+	line := sb.String()
+	if selected {
+		padding := width - len(runes)
+		if padding < 0 {
+			padding = 0
+		}
+		return selectedStyle.Render(line + strings.Repeat(" ", padding))
+	}
+	return line
+}
@@ -0,0 +1,147 @@
+package finder
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8
+	bonusSeparator    = 10
+	bonusCamelCase    = 7
+	bonusConsecutive  = 4
+	bonusFirstChar    = 2
+	penaltyGapStart   = 3
+	penaltyGapExtend  = 1
+	penaltyOutsideTip = 1
+)
+
+// Score reports whether pattern is a subsequence of candidate and how good
+// the match is. Matches at the start of path components and words and runs
+// of consecutive characters score higher, gaps score lower. Matching is
+// case-insensitive unless pattern contains an upper case letter. The
+// returned positions are rune indexes into candidate.
+func Score(pattern, candidate string) (int, []int, bool) {
+	positions := []int{}
+	total, ok := score([]rune(pattern), []rune(candidate), hasUpper(pattern), &positions)
+	if len(positions) == 0 {
+		positions = nil
+	}
+	return total, positions, ok
+}
+
+// score is Score on pre-converted runes. Positions are only collected when
+// positions is not nil, which saves allocations when ranking many paths.
+func score(p, c []rune, caseSensitive bool, positions *[]int) (int, bool) {
+	if len(p) == 0 {
+		return 0, true
+	}
+	if len(p) > len(c) {
+		return 0, false
+	}
+
+	// Forward pass finds where the first complete match ends
+	pi := 0
+	end := -1
+	for ci := 0; ci < len(c); ci++ {
+		if equalFold(p[pi], c[ci], caseSensitive) {
+			pi++
+			if pi == len(p) {
+				end = ci
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+
+	// Backward pass from the end shrinks the window to the tightest match
+	pi = len(p) - 1
+	start := end
+	for ci := end; ci >= 0; ci-- {
+		if equalFold(p[pi], c[ci], caseSensitive) {
+			pi--
+			if pi < 0 {
+				start = ci
+				break
+			}
+		}
+	}
+
+	total := 0
+	pi = 0
+	inGap := false
+	consecutive := 0
+	for ci := start; ci <= end && pi < len(p); ci++ {
+		if !equalFold(p[pi], c[ci], caseSensitive) {
+			if inGap {
+				total -= penaltyGapExtend
+			} else {
+				total -= penaltyGapStart
+				inGap = true
+			}
+			consecutive = 0
+			continue
+		}
+
+		bonus := boundaryBonus(c, ci)
+		if pi == 0 {
+			bonus += bonusFirstChar
+		}
+		if consecutive > 0 {
+			bonus += bonusConsecutive * consecutive
+		}
+
+		total += scoreMatch + bonus
+		if positions != nil {
+			*positions = append(*positions, ci)
+		}
+		consecutive++
+		inGap = false
+		pi++
+	}
+
+	// Prefer shorter candidates among otherwise equal matches
+	total -= (len(c) - len(p)) * penaltyOutsideTip / 4
+
+	return total, true
+}
+
+func boundaryBonus(c []rune, i int) int {
+	if i == 0 {
+		return bonusSeparator
+	}
+
+	prev, cur := c[i-1], c[i]
+	switch {
+	case prev == '/':
+		return bonusSeparator
+	case prev == '_' || prev == '-' || prev == '.' || prev == ' ':
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamelCase
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		return bonusBoundary
+	}
+	return 0
+}
+
+func equalFold(p, c rune, caseSensitive bool) bool {
+	if caseSensitive {
+		return p == c
+	}
+	return p == c || unicode.ToLower(p) == unicode.ToLower(c)
+}
+
+func hasUpper(s string) bool {
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if unicode.IsUpper(r) {
+			return true
+		}
+		s = s[size:]
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScoreTestSuite struct {
+	suite.Suite
+}
+
+func (s *ScoreTestSuite) TestMatch() {
+	tests := []struct {
+		name      string
+		pattern   string
+		candidate string
+		matched   bool
+		positions []int
+	}{
+		{
+			name:      "empty pattern matches everything",
+			pattern:   "",
+			candidate: "main.go",
+			matched:   true,
+			positions: nil,
+		},
+		{
+			name:      "subsequence",
+			pattern:   "mgo",
+			candidate: "main.go",
+			matched:   true,
+			positions: []int{0, 5, 6},
+		},
+		{
+			name:      "out of order characters do not match",
+			pattern:   "og",
+			candidate: "go",
+			matched:   false,
+		},
+		{
+			name:      "pattern longer than candidate",
+			pattern:   "mainfile",
+			candidate: "main",
+			matched:   false,
+		},
+		{
+			name:      "lower case pattern ignores case",
+			pattern:   "readme",
+			candidate: "README.md",
+			matched:   true,
+			positions: []int{0, 1, 2, 3, 4, 5},
+		},
+		{
+			name:      "upper case pattern is case sensitive",
+			pattern:   "Readme",
+			candidate: "README.md",
+			matched:   false,
+		},
+		{
+			name:      "tightest window is reported",
+			pattern:   "ab",
+			candidate: "a/x/ab",
+			matched:   true,
+			positions: []int{4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, positions, ok := Score(tt.pattern, tt.candidate)
+
+			s.Equal(tt.matched, ok, "match result should match")
+			if tt.matched {
+				s.Equal(tt.positions, positions, "positions should match")
+			}
+		})
+	}
+}
+
+func (s *ScoreTestSuite) TestRanking() {
+	tests := []struct {
+		name    string
+		pattern string
+		better  string
+		worse   string
+	}{
+		{
+			name:    "consecutive beats scattered",
+			pattern: "view",
+			better:  "internal/view.go",
+			worse:   "internal/vim/editor/w.go",
+		},
+		{
+			name:    "path component start beats middle of word",
+			pattern: "hook",
+			better:  "filemanager/hook/file.go",
+			worse:   "filemanager/rehooked.go",
+		},
+		{
+			name:    "shorter candidate wins a tie",
+			pattern: "main",
+			better:  "main.go",
+			worse:   "main_windows_amd64.go",
+		},
+		{
+			name:    "camel case boundary beats inner match",
+			pattern: "fm",
+			better:  "FileManager.go",
+			worse:   "xfxm.go",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			better, _, ok := Score(tt.pattern, tt.better)
+			s.True(ok)
+			worse, _, ok := Score(tt.pattern, tt.worse)
+			s.True(ok)
+
+			s.Greater(better, worse, "%q should rank above %q", tt.better, tt.worse)
+		})
+	}
+}
+
+func TestScoreSuite(t *testing.T) {
+	suite.Run(t, new(ScoreTestSuite))
+}
@@ -0,0 +1,87 @@
+package finder
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/gunererd/grease/internal/filemanager/ignore"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// walker collects paths below a root in the background. Directories are
+// recorded with a trailing slash, paths are relative to root.
+type walker struct {
+	mu     sync.Mutex
+	paths  []string
+	done   bool
+	cancel context.CancelFunc
+}
+
+func newWalker(fsys types.FileSystem, root string, ignored types.IgnoreMatcher, logger types.Logger) *walker {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &walker{cancel: cancel}
+
+	go func() {
+		if err := w.walk(ctx, fsys, root, "", ignore.NewWalk(fsys, root, ignored)); err != nil {
+			logger.Println("Finder walk failed:", err)
+		}
+
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	}()
+
+	return w
+}
+
+// walk records the entries below root/rel. Only an unreadable root ends the
+// walk, unreadable directories below it are skipped.
+func (w *walker) walk(ctx context.Context, fsys types.FileSystem, root, rel string, ignored *ignore.Walk) error {
+	entries, err := fsys.ReadDir(filepath.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	ignored.Enter(rel)
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		entryRel := filepath.Join(rel, entry.Name())
+		if ignored.Match(entryRel, entry.IsDir()) {
+			continue
+		}
+
+		if !entry.IsDir() {
+			w.add(entryRel)
+			continue
+		}
+		w.add(entryRel + "/")
+		w.walk(ctx, fsys, root, entryRel, ignored)
+	}
+	return nil
+}
+
+func (w *walker) add(path string) {
+	w.mu.Lock()
+	w.paths = append(w.paths, path)
+	w.mu.Unlock()
+}
+
+// since returns the paths found after the first n and whether the walk has
+// finished
+func (w *walker) since(n int) ([]string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n >= len(w.paths) {
+		return nil, w.done
+	}
+	return w.paths[n:len(w.paths):len(w.paths)], w.done
+}
+
+func (w *walker) stop() {
+	w.cancel()
+}
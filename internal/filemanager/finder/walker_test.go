@@ -0,0 +1,48 @@
+package finder
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/ignore"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type WalkerTestSuite struct {
+	suite.Suite
+}
+
+func (s *WalkerTestSuite) TestWalksFileSystem() {
+	fs := vfs.NewMemory()
+	s.Require().NoError(fs.MkdirAll("/root/src/.git", 0755))
+	s.Require().NoError(fs.MkdirAll("/root/src/build", 0755))
+	for _, path := range []string{"/root/a.txt", "/root/src/main.go", "/root/src/.git/HEAD", "/root/src/build/out.o"} {
+		f, err := fs.Create(path, 0644)
+		s.Require().NoError(err)
+		s.Require().NoError(f.Close())
+	}
+
+	f, err := fs.Create("/root/src/.gitignore", 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte("build/\n"))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+
+	w := newWalker(fs, "/root", ignore.New(ignore.DefaultPatterns), log.New(io.Discard, "", 0))
+	defer w.stop()
+
+	var paths []string
+	s.Eventually(func() bool {
+		found, done := w.since(len(paths))
+		paths = append(paths, found...)
+		return done
+	}, time.Second, time.Millisecond)
+	s.ElementsMatch([]string{"a.txt", "src/", "src/.gitignore", "src/main.go"}, paths)
+}
+
+func TestWalkerSuite(t *testing.T) {
+	suite.Run(t, new(WalkerTestSuite))
+}
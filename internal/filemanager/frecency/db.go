@@ -0,0 +1,258 @@
+// Package frecency keeps the directories visited in a file along with how
+// often and when they were last visited, the way z and zoxide do
+package frecency
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// MaxAge is the sum of all ranks past which they are scaled down, so that
+// directories no longer visited eventually drop out
+const MaxAge = 10000
+
+type entry struct {
+	rank float64
+	last time.Time
+}
+
+// DB stores one "<path>|<rank>|<unix time>" line per directory. The file is
+// read again before every change under a lock on "<file>.lock", so that
+// instances running side by side do not drop each other's visits.
+type DB struct {
+	mu      sync.Mutex
+	file    string
+	fs      types.FileSystem
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+// New returns a database kept in file. fs is asked whether the directories
+// found by Query still exist.
+func New(file string, fs types.FileSystem) types.Frecency {
+	return &DB{
+		file: file,
+		fs:   fs,
+		now:  time.Now,
+	}
+}
+
+func (db *DB) Add(dir string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	unlock, err := db.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := db.load(); err != nil {
+		return err
+	}
+
+	e, ok := db.entries[dir]
+	if !ok {
+		e = &entry{}
+		db.entries[dir] = e
+	}
+	e.rank++
+	e.last = db.now()
+	db.age()
+	return db.save()
+}
+
+func (db *DB) Query(keywords ...string) ([]string, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	unlock, err := db.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if err := db.load(); err != nil {
+		return nil, err
+	}
+
+	now := db.now()
+	var matches []string
+	pruned := false
+	for dir := range db.entries {
+		if !Matches(dir, keywords) {
+			continue
+		}
+		if info, err := db.fs.Stat(dir); err != nil || !info.IsDir() {
+			delete(db.entries, dir)
+			pruned = true
+			continue
+		}
+		matches = append(matches, dir)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := db.score(matches[i], now), db.score(matches[j], now)
+		if a != b {
+			return a > b
+		}
+		return matches[i] < matches[j]
+	})
+
+	if pruned {
+		if err := db.save(); err != nil {
+			return matches, err
+		}
+	}
+	return matches, nil
+}
+
+// score weighs the rank of dir by how long ago it was last visited
+func (db *DB) score(dir string, now time.Time) float64 {
+	e := db.entries[dir]
+	switch since := now.Sub(e.last); {
+	case since < time.Hour:
+		return e.rank * 4
+	case since < 24*time.Hour:
+		return e.rank * 2
+	case since < 7*24*time.Hour:
+		return e.rank / 2
+	default:
+		return e.rank / 4
+	}
+}
+
+// age scales all ranks down once their sum passes MaxAge, forgetting
+// directories whose rank falls below one
+func (db *DB) age() {
+	var total float64
+	for _, e := range db.entries {
+		total += e.rank
+	}
+	if total <= MaxAge {
+		return
+	}
+
+	factor := 0.9 * MaxAge / total
+	for dir, e := range db.entries {
+		e.rank *= factor
+		if e.rank < 1 {
+			delete(db.entries, dir)
+		}
+	}
+}
+
+// Matches reports whether keywords appear in dir in order, ignoring case,
+// with the last one in its final element
+func Matches(dir string, keywords []string) bool {
+	path := strings.ToLower(dir)
+	rest := path
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		i := strings.Index(rest, keyword)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(keyword):]
+	}
+
+	if len(keywords) == 0 {
+		return true
+	}
+	last := strings.ToLower(keywords[len(keywords)-1])
+	return strings.Contains(filepath.Base(path), last)
+}
+
+// lock keeps other instances from changing the file until unlock is called
+func (db *DB) lock() (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(db.file), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create frecency directory: %w", err)
+	}
+	unlock, err = lock(db.file + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock frecency database: %w", err)
+	}
+	return unlock, nil
+}
+
+func (db *DB) load() error {
+	db.entries = make(map[string]*entry)
+
+	f, err := os.Open(db.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Paths may contain "|" themselves, the numbers never do
+		line := scanner.Text()
+		rest, last, ok1 := cutLast(line, "|")
+		dir, rank, ok2 := cutLast(rest, "|")
+		if !ok1 || !ok2 || dir == "" {
+			continue
+		}
+
+		r, err1 := strconv.ParseFloat(rank, 64)
+		t, err2 := strconv.ParseInt(last, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		db.entries[dir] = &entry{rank: r, last: time.Unix(t, 0)}
+	}
+	return scanner.Err()
+}
+
+func (db *DB) save() error {
+	dirs := make([]string, 0, len(db.entries))
+	for dir := range db.entries {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var sb strings.Builder
+	for _, dir := range dirs {
+		e := db.entries[dir]
+		fmt.Fprintf(&sb, "%s|%s|%d\n", dir, strconv.FormatFloat(e.rank, 'f', -1, 64), e.last.Unix())
+	}
+
+	// Written aside and renamed so that the file is never seen half written
+	tmp, err := os.CreateTemp(filepath.Dir(db.file), filepath.Base(db.file)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write frecency database: %w", err)
+	}
+	if _, err = tmp.WriteString(sb.String()); err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), db.file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write frecency database: %w", err)
+	}
+	return nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
@@ -0,0 +1,180 @@
+package frecency
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type DBTestSuite struct {
+	suite.Suite
+	fs   types.FileSystem
+	file string
+	now  time.Time
+}
+
+func (s *DBTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	for _, dir := range []string{"/home/me/src/grease", "/home/me/src/other", "/home/me/docs", "/tmp/Grease"} {
+		s.Require().NoError(s.fs.MkdirAll(dir, 0755))
+	}
+	s.file = filepath.Join(s.T().TempDir(), "grease", "frecency")
+	s.now = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+}
+
+func (s *DBTestSuite) db() *DB {
+	db := New(s.file, s.fs).(*DB)
+	db.now = func() time.Time { return s.now }
+	return db
+}
+
+func (s *DBTestSuite) visit(db *DB, dir string, times int) {
+	for i := 0; i < times; i++ {
+		s.Require().NoError(db.Add(dir))
+	}
+}
+
+func (s *DBTestSuite) TestRanksByVisitsAndRecency() {
+	db := s.db()
+	s.visit(db, "/home/me/docs", 3)
+	s.visit(db, "/home/me/src/other", 2)
+
+	// Visited less, but within the last hour against two days ago
+	s.now = s.now.Add(48 * time.Hour)
+	s.visit(db, "/home/me/src/grease", 1)
+
+	dirs, err := db.Query()
+	s.Require().NoError(err)
+	s.Equal([]string{"/home/me/src/grease", "/home/me/docs", "/home/me/src/other"}, dirs)
+}
+
+func (s *DBTestSuite) TestMatches() {
+	tests := []struct {
+		name     string
+		dir      string
+		keywords []string
+		want     bool
+	}{
+		{"no keywords", "/home/me/src", nil, true},
+		{"last element", "/home/me/src/grease", []string{"gre"}, true},
+		{"ignores case", "/tmp/Grease", []string{"grease"}, true},
+		{"in order", "/home/me/src/grease", []string{"src", "gr"}, true},
+		{"out of order", "/home/me/src/grease", []string{"gr", "src"}, false},
+		{"not in last element", "/home/me/src/grease", []string{"src"}, false},
+		{"missing", "/home/me/src/grease", []string{"docs"}, false},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.want, Matches(tt.dir, tt.keywords))
+		})
+	}
+}
+
+func (s *DBTestSuite) TestQueryFilters() {
+	db := s.db()
+	s.visit(db, "/home/me/src/grease", 1)
+	s.visit(db, "/tmp/Grease", 2)
+	s.visit(db, "/home/me/docs", 5)
+
+	dirs, err := db.Query("grease")
+	s.Require().NoError(err)
+	s.Equal([]string{"/tmp/Grease", "/home/me/src/grease"}, dirs)
+
+	dirs, err = db.Query("src", "grease")
+	s.Require().NoError(err)
+	s.Equal([]string{"/home/me/src/grease"}, dirs)
+}
+
+func (s *DBTestSuite) TestAging() {
+	db := s.db()
+	s.visit(db, "/home/me/docs", 1)
+	db.entries["/home/me/src/grease"] = &entry{rank: MaxAge, last: s.now}
+	s.Require().NoError(db.save())
+
+	// Pushes the sum past MaxAge, scaling docs below one
+	s.visit(db, "/home/me/src/other", 1)
+
+	dirs, err := db.Query()
+	s.Require().NoError(err)
+	s.Equal([]string{"/home/me/src/grease"}, dirs)
+	s.InDelta(0.9*MaxAge*MaxAge/(MaxAge+2), db.entries["/home/me/src/grease"].rank, 0.001)
+}
+
+func (s *DBTestSuite) TestPrunesMissingDirectories() {
+	db := s.db()
+	s.visit(db, "/home/me/src/grease", 1)
+	s.visit(db, "/home/me/src/other", 1)
+	s.Require().NoError(s.fs.RemoveAll("/home/me/src/other"))
+
+	dirs, err := db.Query()
+	s.Require().NoError(err)
+	s.Equal([]string{"/home/me/src/grease"}, dirs)
+
+	data, err := os.ReadFile(s.file)
+	s.Require().NoError(err)
+	s.NotContains(string(data), "/home/me/src/other")
+}
+
+func (s *DBTestSuite) TestPersists() {
+	s.visit(s.db(), "/home/me/src/grease", 2)
+
+	// Broken lines are skipped, paths may contain "|"
+	f, err := os.OpenFile(s.file, os.O_WRONLY|os.O_APPEND, 0)
+	s.Require().NoError(err)
+	_, err = f.WriteString("garbage\n/tmp/Grease|x|1\n")
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+	s.Require().NoError(s.fs.MkdirAll("/home/a|b", 0755))
+	s.visit(s.db(), "/home/a|b", 1)
+
+	db := s.db()
+	dirs, err := db.Query()
+	s.Require().NoError(err)
+	s.Equal([]string{"/home/me/src/grease", "/home/a|b"}, dirs)
+	s.Equal(2.0, db.entries["/home/me/src/grease"].rank)
+}
+
+// Instances running side by side keep each other's visits
+func (s *DBTestSuite) TestConcurrentInstances() {
+	dirs := []string{"/home/me/src/grease", "/home/me/src/other", "/home/me/docs", "/tmp/Grease"}
+
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(db *DB, dir string) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				s.NoError(db.Add(dir))
+			}
+		}(s.db(), dir)
+	}
+	wg.Wait()
+
+	db := s.db()
+	found, err := db.Query()
+	s.Require().NoError(err)
+	s.ElementsMatch(dirs, found)
+	for _, dir := range dirs {
+		s.Equal(20.0, db.entries[dir].rank, dir)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(s.file))
+	s.Require().NoError(err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	s.ElementsMatch([]string{"frecency", "frecency.lock"}, names)
+}
+
+func TestDBSuite(t *testing.T) {
+	suite.Run(t, new(DBTestSuite))
+}
@@ -0,0 +1,166 @@
+package gitstatus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Status is the git state of a directory entry. Higher values win when a
+// directory is rolled up from its children.
+type Status int
+
+const (
+	Clean Status = iota
+	Ignored
+	Untracked
+	Staged
+	Modified
+	Conflicted
+)
+
+func (s Status) String() string {
+	switch s {
+	case Ignored:
+		return "ignored"
+	case Untracked:
+		return "untracked"
+	case Staged:
+		return "staged"
+	case Modified:
+		return "modified"
+	case Conflicted:
+		return "conflicted"
+	default:
+		return "clean"
+	}
+}
+
+// Read runs git status in dir and returns the status of its entries, keyed
+// by name as listed (directories with a trailing slash). Clean entries are
+// left out. It fails when dir is not inside a work tree.
+func Read(ctx context.Context, dir string) (map[string]Status, error) {
+	prefix, err := git(ctx, dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := git(ctx, dir, "status", "--porcelain=v2", "-z", "--ignored=matching", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(strings.TrimSpace(string(prefix)), out), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// Parse reads the NUL separated output of git status --porcelain=v2 for the
+// directory at prefix, relative to the top of the work tree. Paths below an
+// entry are rolled up into it, except ignored ones: a directory holding an
+// ignored file is not ignored itself.
+func Parse(prefix string, out []byte) map[string]Status {
+	statuses := make(map[string]Status)
+	records := strings.Split(string(out), "\x00")
+
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		var status Status
+		var path string
+		switch record[0] {
+		case '1':
+			status, path = changed(record, 8)
+		case '2':
+			status, path = changed(record, 9)
+			// The original path of a rename or copy follows as its own record
+			i++
+		case 'u':
+			status, path = Conflicted, field(record, 10)
+		case '?':
+			status, path = Untracked, record[2:]
+		case '!':
+			status, path = Ignored, record[2:]
+		default:
+			continue
+		}
+
+		name, below, ok := child(prefix, path)
+		if !ok || status == Clean || (below && status == Ignored) {
+			continue
+		}
+		if status > statuses[name] {
+			statuses[name] = status
+		}
+	}
+
+	return statuses
+}
+
+// changed reads an ordinary or renamed entry whose path is field n. Changes
+// in the work tree win over staged ones.
+func changed(record string, n int) (Status, string) {
+	path := field(record, n)
+	parts := strings.SplitN(record, " ", 3)
+	if len(parts) < 3 || len(parts[1]) != 2 {
+		return Clean, path
+	}
+	xy := parts[1]
+
+	switch {
+	case xy[1] != '.':
+		return Modified, path
+	case xy[0] != '.':
+		return Staged, path
+	default:
+		return Clean, path
+	}
+}
+
+// field returns the space separated fields of record from n on, which for
+// the path, the last field, keeps any spaces in it
+func field(record string, n int) string {
+	parts := strings.SplitN(record, " ", n+1)
+	if len(parts) <= n {
+		return ""
+	}
+	return parts[n]
+}
+
+// child returns the name of the entry of the prefix directory that path is
+// or lies below
+func child(prefix, path string) (name string, below bool, ok bool) {
+	if path == "" || !strings.HasPrefix(path, prefix) {
+		return "", false, false
+	}
+
+	rest := path[len(prefix):]
+	i := strings.Index(rest, "/")
+	switch {
+	case rest == "":
+		return "", false, false
+	case i < 0:
+		return rest, false, true
+	default:
+		// A directory reported as a whole ends with its slash
+		return rest[:i+1], i+1 < len(rest), true
+	}
+}
@@ -0,0 +1,107 @@
+package gitstatus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusTestSuite struct {
+	suite.Suite
+}
+
+func (s *StatusTestSuite) TestParse() {
+	tests := []struct {
+		name     string
+		prefix   string
+		records  []string
+		expected map[string]Status
+	}{
+		{
+			name:   "entries at the top",
+			prefix: "",
+			records: []string{
+				"1 .M N... 100644 100644 100644 abc abc main.go",
+				"1 M. N... 100644 100644 100644 abc def go.mod",
+				"? notes.txt",
+				"! build/",
+				"u UU N... 100644 100644 100644 100644 a b c merge.go",
+			},
+			expected: map[string]Status{
+				"main.go":   Modified,
+				"go.mod":    Staged,
+				"notes.txt": Untracked,
+				"build/":    Ignored,
+				"merge.go":  Conflicted,
+			},
+		},
+		{
+			name:   "staged and modified shows modified",
+			prefix: "",
+			records: []string{
+				"1 MM N... 100644 100644 100644 abc def main.go",
+			},
+			expected: map[string]Status{"main.go": Modified},
+		},
+		{
+			name:   "directories roll up their children",
+			prefix: "",
+			records: []string{
+				"1 M. N... 100644 100644 100644 abc def internal/a.go",
+				"1 .M N... 100644 100644 100644 abc abc internal/deep/b.go",
+				"? docs/new.md",
+				"! docs/out.html",
+			},
+			expected: map[string]Status{
+				"internal/": Modified,
+				"docs/":     Untracked,
+			},
+		},
+		{
+			name:   "ignored children do not mark their directory",
+			prefix: "",
+			records: []string{
+				"! cache/tmp.bin",
+			},
+			expected: map[string]Status{},
+		},
+		{
+			name:   "paths outside the directory are dropped",
+			prefix: "internal/",
+			records: []string{
+				"1 .M N... 100644 100644 100644 abc abc internal/a.go",
+				"1 .M N... 100644 100644 100644 abc abc main.go",
+				"? internal/sub/",
+			},
+			expected: map[string]Status{
+				"a.go": Modified,
+				"sub/": Untracked,
+			},
+		},
+		{
+			name:   "renames skip the original path",
+			prefix: "",
+			records: []string{
+				"2 R. N... 100644 100644 100644 abc abc R100 new name.go",
+				"old.go",
+				"? other.go",
+			},
+			expected: map[string]Status{
+				"new name.go": Staged,
+				"other.go":    Untracked,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			out := []byte(strings.Join(tt.records, "\x00") + "\x00")
+			s.Equal(tt.expected, Parse(tt.prefix, out))
+		})
+	}
+}
+
+func TestStatusSuite(t *testing.T) {
+	suite.Run(t, new(StatusTestSuite))
+}
@@ -0,0 +1,148 @@
+// Package grep searches the contents of directory trees
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gunererd/grease/internal/filemanager/ignore"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const (
+	// binaryProbe is how much of a file is checked for a NUL byte, which
+	// marks it as binary and skips it
+	binaryProbe = 8000
+	// maxLine is the longest line read, files with longer ones are only
+	// searched up to there
+	maxLine = 1 << 20
+)
+
+// Grep walks the tree in one goroutine and hands the files to workers that
+// search them. Entries matched by the ignore rules or the .gitignore files
+// of the tree are skipped like in the finder, symlinks are not followed and
+// unreadable files are left out.
+type Grep struct {
+	fs      types.FileSystem
+	ignore  types.IgnoreMatcher
+	workers int
+	logger  types.Logger
+}
+
+func New(fs types.FileSystem, ignore types.IgnoreMatcher, workers int, logger types.Logger) types.Searcher {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Grep{
+		fs:      fs,
+		ignore:  ignore,
+		workers: workers,
+		logger:  logger,
+	}
+}
+
+func (g *Grep) Search(ctx context.Context, root string, pattern *regexp.Regexp) <-chan types.SearchMatch {
+	files := make(chan string)
+	matches := make(chan types.SearchMatch)
+
+	go func() {
+		defer close(files)
+		g.walk(ctx, root, "", ignore.NewWalk(g.fs, root, g.ignore), files)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < g.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rel := range files {
+				if err := g.searchFile(ctx, root, rel, pattern, matches); err != nil {
+					g.logger.Println("Failed to search", rel+":", err)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(matches)
+	}()
+	return matches
+}
+
+// walk sends the regular files below root/rel, relative to root
+func (g *Grep) walk(ctx context.Context, root, rel string, ignored *ignore.Walk, files chan<- string) {
+	entries, err := g.fs.ReadDir(path.Join(root, rel))
+	if err != nil {
+		g.logger.Println("Failed to read", path.Join(root, rel)+":", err)
+		return
+	}
+	ignored.Enter(rel)
+
+	for _, entry := range entries {
+		entryRel := path.Join(rel, entry.Name())
+		if ignored.Match(entryRel, entry.IsDir()) {
+			continue
+		}
+
+		switch {
+		case entry.IsDir():
+			g.walk(ctx, root, entryRel, ignored, files)
+		case entry.Type().IsRegular():
+			select {
+			case files <- entryRel:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (g *Grep) searchFile(ctx context.Context, root, rel string, pattern *regexp.Regexp, matches chan<- types.SearchMatch) error {
+	f, err := g.fs.Open(path.Join(root, rel))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, binaryProbe)
+	if probe, err := reader.Peek(binaryProbe); err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return err
+	} else if bytes.IndexByte(probe, 0) >= 0 {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !pattern.Match(scanner.Bytes()) {
+			continue
+		}
+
+		match := types.SearchMatch{
+			Path: rel,
+			Line: line,
+			Text: strings.TrimSuffix(scanner.Text(), "\r"),
+		}
+		select {
+		case matches <- match:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil && err != bufio.ErrTooLong {
+		return err
+	}
+	return nil
+}
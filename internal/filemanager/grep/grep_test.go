@@ -0,0 +1,121 @@
+package grep
+
+import (
+	"context"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/ignore"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type GrepTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *GrepTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/work/src/deep", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/work/.git", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/work/build", 0755))
+	s.writeFile("/work/a.txt", "needle\nhay\nmore needle\r\n")
+	s.writeFile("/work/src/deep/b.go", "hay\nNeedle\n")
+	s.writeFile("/work/.git/config", "needle\n")
+	s.writeFile("/work/build/out.txt", "needle\n")
+	s.writeFile("/work/image.bin", "needle\x00")
+	s.Require().NoError(s.fs.Symlink("/work/a.txt", "/work/link"))
+}
+
+func (s *GrepTestSuite) writeFile(path, content string) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(content))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+func (s *GrepTestSuite) search(pattern string, workers int) []types.SearchMatch {
+	ignored := ignore.New(append([]string{"build/"}, ignore.DefaultPatterns...))
+	g := New(s.fs, ignored, workers, log.New(io.Discard, "", 0))
+
+	var matches []types.SearchMatch
+	for m := range g.Search(context.Background(), "/work", regexp.MustCompile(pattern)) {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Path != matches[j].Path {
+			return matches[i].Path < matches[j].Path
+		}
+		return matches[i].Line < matches[j].Line
+	})
+	return matches
+}
+
+func (s *GrepTestSuite) TestSearch() {
+	for _, workers := range []int{1, 4} {
+		s.Equal([]types.SearchMatch{
+			{Path: "a.txt", Line: 1, Text: "needle"},
+			{Path: "a.txt", Line: 3, Text: "more needle"},
+			{Path: "src/deep/b.go", Line: 2, Text: "Needle"},
+		}, s.search("(?i)needle", workers))
+	}
+}
+
+func (s *GrepTestSuite) TestCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := New(s.fs, ignore.New(nil), 2, log.New(io.Discard, "", 0))
+	for range g.Search(ctx, "/work", regexp.MustCompile("needle")) {
+	}
+}
+
+func (s *GrepTestSuite) TestFormatAndParse() {
+	tests := []struct {
+		line string
+		want types.SearchMatch
+	}{
+		{"a.txt:1:needle", types.SearchMatch{Path: "a.txt", Line: 1, Text: "needle"}},
+		{"src/b.go:12:x := a:3:b", types.SearchMatch{Path: "src/b.go", Line: 12, Text: "x := a:3:b"}},
+		{"empty:7:", types.SearchMatch{Path: "empty", Line: 7}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.line, func() {
+			m, err := Parse(tt.line)
+			s.Require().NoError(err)
+			s.Equal(tt.want, m)
+			s.Equal(tt.line, Format(m))
+		})
+	}
+
+	for _, line := range []string{"", "a.txt", "a.txt:x:needle", ":1:needle"} {
+		_, err := Parse(line)
+		s.Error(err, line)
+	}
+}
+
+func (s *GrepTestSuite) TestOpenLooksUpLines() {
+	var opened []string
+	var lines []int
+	scratch := NewScratch("/work", []types.SearchMatch{{Path: "a:1:b", Line: 4, Text: "x"}}, func(path string, line int) error {
+		opened = append(opened, path)
+		lines = append(lines, line)
+		return nil
+	})
+
+	s.Require().NoError(scratch.Open("a:1:b:4:x"))
+	s.Require().NoError(scratch.Open("c.txt:2:edited"))
+	s.Equal([]string{"/work/a:1:b", "/work/c.txt"}, opened)
+	s.Equal([]int{4, 2}, lines)
+}
+
+func TestGrepSuite(t *testing.T) {
+	suite.Run(t, new(GrepTestSuite))
+}
@@ -0,0 +1,84 @@
+package grep
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/gunererd/grease/internal/filemanager/scratch"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// resultLine splits "path:line:text", taking the first ":<number>:" as the
+// end of the path
+var resultLine = regexp.MustCompile(`^(.+?):(\d+):`)
+
+// Scratch lists search results as "path:line:text". Enter opens the file of
+// a result on its line.
+type Scratch struct {
+	root    string
+	matches []types.SearchMatch
+	open    func(path string, line int) error
+}
+
+// NewScratch shows matches found below root, open is called with the
+// absolute path of a chosen result
+func NewScratch(root string, matches []types.SearchMatch, open func(path string, line int) error) types.Scratch {
+	return &Scratch{
+		root:    root,
+		matches: matches,
+		open:    open,
+	}
+}
+
+func (s *Scratch) Name() string {
+	return "grep"
+}
+
+func (s *Scratch) Lines() ([]string, error) {
+	lines := make([]string, len(s.matches))
+	for i, m := range s.matches {
+		lines[i] = Format(m)
+	}
+	return lines, nil
+}
+
+func (s *Scratch) Open(line string) error {
+	// Paths like "a:1:b" cannot be told apart when parsing, the unedited
+	// lines are looked up instead
+	for _, m := range s.matches {
+		if Format(m) == line {
+			return s.open(filepath.Join(s.root, m.Path), m.Line)
+		}
+	}
+
+	m, err := Parse(line)
+	if err != nil {
+		return err
+	}
+	return s.open(filepath.Join(s.root, m.Path), m.Line)
+}
+
+func (s *Scratch) Write(lines []string) error {
+	return scratch.ErrReadOnly
+}
+
+// Format renders m as "path:line:text"
+func Format(m types.SearchMatch) string {
+	return fmt.Sprintf("%s:%d:%s", m.Path, m.Line, m.Text)
+}
+
+// Parse reads a line rendered by Format back
+func Parse(line string) (types.SearchMatch, error) {
+	parts := resultLine.FindStringSubmatch(line)
+	if parts == nil {
+		return types.SearchMatch{}, fmt.Errorf("not a search result: %q", line)
+	}
+
+	n, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return types.SearchMatch{}, fmt.Errorf("not a search result: %q", line)
+	}
+	return types.SearchMatch{Path: parts[1], Line: n, Text: line[len(parts[0]):]}, nil
+}
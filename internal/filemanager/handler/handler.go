@@ -1,72 +1,228 @@
 package handler
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/editor/state"
 	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/picker"
 	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
 )
 
 type Handler struct {
 	dirManager types.DirectoryManager
+	bookmarks  types.BookmarkManager
+	history    types.NavigationHistory
+	finder     types.Finder
+	picker     types.Picker // nil unless grease runs as a file chooser
 	editor     eTypes.Editor
 	loadDir    func(string) error
+	scratch    func() types.Scratch
+	pending    string // first key of a two key sequence such as "ma"
 	logger     types.Logger
 }
 
 func New(
 	dirManager types.DirectoryManager,
+	bookmarks types.BookmarkManager,
+	history types.NavigationHistory,
+	finder types.Finder,
+	picker types.Picker,
 	editor eTypes.Editor,
 	loadDir func(string) error,
+	scratch func() types.Scratch,
 	logger types.Logger,
 ) *Handler {
 	return &Handler{
 		dirManager: dirManager,
+		bookmarks:  bookmarks,
+		history:    history,
+		finder:     finder,
+		picker:     picker,
 		editor:     editor,
 		loadDir:    loadDir,
+		scratch:    scratch,
 		logger:     logger,
 	}
 }
 
-func (h *Handler) Handle(msg tea.KeyMsg) (tea.Cmd, error) {
+// Handle processes filemanager keys in normal mode. It reports whether the
+// key was consumed so that it is not passed on to the editor.
+func (h *Handler) Handle(msg tea.KeyMsg) (bool, tea.Cmd, error) {
+	if h.editor.Mode() != state.NormalMode {
+		h.pending = ""
+		return false, nil, nil
+	}
+
+	if h.pending != "" {
+		pending := h.pending
+		h.pending = ""
+		return true, nil, h.handleMark(pending, msg)
+	}
+
+	if scratch, ok := h.scratch().(types.KeyedScratch); ok {
+		line, err := h.editor.Buffer().GetLine(h.cursorLine())
+		if err != nil {
+			return true, nil, err
+		}
+		used, err := scratch.Key(msg.String(), line)
+		if err != nil {
+			h.editor.SetMessage(err.Error(), true)
+		}
+		if used {
+			return true, nil, nil
+		}
+	}
+
 	switch msg.String() {
 	case "enter":
 		h.logger.Println("Enter key pressed")
 		cursor, err := h.editor.Buffer().GetPrimaryCursor()
 		if err != nil {
-			return nil, err
+			return true, nil, err
 		}
 
 		line := cursor.GetPosition().Line()
 		content, err := h.editor.Buffer().GetLine(line)
 		if err != nil {
-			return nil, err
+			return true, nil, err
+		}
+
+		if scratch := h.scratch(); scratch != nil {
+			err := scratch.Open(content)
+			if err != nil {
+				h.editor.SetMessage(err.Error(), true)
+			}
+			return true, nil, err
+		}
+
+		if h.picker != nil && h.pick(content) {
+			return true, tea.Quit, nil
 		}
 
-		if content[len(content)-1] == '/' {
+		if len(content) > 0 && content[len(content)-1] == '/' {
 			dirName := content[:len(content)-1]
 			newPath := filepath.Join(h.dirManager.CurrentPath(), dirName)
 
-			if err := h.dirManager.ChangeDirectory(newPath); err != nil {
-				return nil, err
-			}
+			return true, nil, h.loadDir(newPath)
+		}
 
-			return nil, h.loadDir(newPath)
+		// Archives are browsed like directories
+		if vfs.IsArchive(content) {
+			return true, nil, h.loadDir(filepath.Join(h.dirManager.CurrentPath(), content))
 		}
+		return true, nil, nil
 
 	case "-":
+		if h.scratch() != nil {
+			return true, nil, h.loadDir(h.dirManager.CurrentPath())
+		}
+
 		if h.dirManager.CurrentPath() != "/" {
 			h.logger.Println("Back key pressed")
 			parentDir := filepath.Dir(h.dirManager.CurrentPath())
 
-			if err := h.dirManager.ChangeDirectory(parentDir); err != nil {
-				return nil, err
-			}
+			return true, nil, h.loadDir(parentDir)
+		}
+		return true, nil, nil
+
+	case "ctrl+o":
+		if path, ok := h.history.Back(); ok {
+			return true, nil, h.loadDir(path)
+		}
+		return true, nil, nil
 
-			return nil, h.loadDir(parentDir)
+	// ctrl+i is indistinguishable from tab in most terminals
+	case "tab", "ctrl+i":
+		if path, ok := h.history.Forward(); ok {
+			return true, nil, h.loadDir(path)
 		}
+		return true, nil, nil
+
+	case "ctrl+p":
+		return true, h.finder.Open(h.dirManager.CurrentPath()), nil
+
+	case " ":
+		if h.picker == nil || h.scratch() != nil {
+			return false, nil, nil
+		}
+		entry, err := h.editor.Buffer().GetLine(h.cursorLine())
+		if err != nil || entry == "" {
+			return true, nil, err
+		}
+		h.toggle(entry)
+		return true, nil, nil
+
+	case "m", "'":
+		h.pending = msg.String()
+		return true, nil, nil
+	}
+
+	return false, nil, nil
+}
+
+// pick ends picking when enter is pressed on a file: with the marked
+// entries when there are any, otherwise with the file itself. Directories
+// and archives are entered as usual.
+func (h *Handler) pick(entry string) bool {
+	if entry == "" || strings.HasSuffix(entry, "/") || vfs.IsArchive(entry) {
+		return false
+	}
+	if len(h.picker.Marks()) == 0 && !h.toggle(entry) {
+		return false
+	}
+
+	h.picker.Confirm()
+	return true
+}
+
+// toggle marks or unmarks entry of the current directory to be picked,
+// telling the user why when it cannot be
+func (h *Handler) toggle(entry string) bool {
+	if h.dirManager.IsReadOnly() {
+		h.editor.SetMessage("Entries inside archives cannot be picked", true)
+		return false
+	}
+
+	path, isDir := picker.Entry(h.dirManager.CurrentPath(), entry)
+	if err := h.picker.Toggle(path, isDir); err != nil {
+		h.editor.SetMessage(fmt.Sprintf("Cannot pick %s: %v", entry, err), true)
+		return false
+	}
+	h.editor.SetMessage(fmt.Sprintf("%d marked", len(h.picker.Marks())), false)
+	return true
+}
+
+func (h *Handler) cursorLine() int {
+	cursor, err := h.editor.Buffer().GetPrimaryCursor()
+	if err != nil {
+		return 0
+	}
+	return cursor.GetPosition().Line()
+}
+
+// handleMark completes "m{a-z}" (set bookmark) and "'{a-z}" (jump to it)
+func (h *Handler) handleMark(pending string, msg tea.KeyMsg) error {
+	if len(msg.Runes) != 1 {
+		return nil
 	}
+	mark := msg.Runes[0]
 
-	return nil, nil
+	switch pending {
+	case "m":
+		h.logger.Printf("Setting bookmark %c to %s", mark, h.dirManager.CurrentPath())
+		return h.bookmarks.Set(mark, h.dirManager.CurrentPath())
+	case "'":
+		path, ok := h.bookmarks.Get(mark)
+		if !ok {
+			h.logger.Printf("Bookmark %c is not set", mark)
+			return nil
+		}
+		return h.loadDir(path)
+	}
+	return nil
 }
@@ -4,102 +4,94 @@ import (
 	"path/filepath"
 
 	eTypes "github.com/gunererd/grease/internal/editor/types"
-	"github.com/gunererd/grease/internal/filemanager/operation"
 	types "github.com/gunererd/grease/internal/filemanager/types"
 )
 
+// clipboardEntry is a deleted line that may be pasted elsewhere. Entries
+// taken from a read-only directory are copied instead of moved.
+type clipboardEntry struct {
+	path string
+	copy bool
+}
+
+// FileOperationHook remembers where deleted lines were cut from, so that
+// pasting them into another directory moves the entry, and saves the
+// listing on write. What the edits amount to is worked out on save by
+// comparing the listing with the directory, see reconcile.Plan.
 type FileOperationHook struct {
 	dirManager types.DirectoryManager
-	opManager  types.OperationManager
-	clipboard  map[string]string // stores original paths of deleted/moved files
+	clipboard  map[string]clipboardEntry // stores original paths of deleted lines
+	deleting   string                    // line under the cursor before delete_line ran
+	save       func()
 	logger     types.Logger
 }
 
-func NewFileOperationHook(dirManager types.DirectoryManager, opManager types.OperationManager) *FileOperationHook {
+func NewFileOperationHook(
+	dirManager types.DirectoryManager,
+	save func(),
+	logger types.Logger,
+) *FileOperationHook {
 	return &FileOperationHook{
 		dirManager: dirManager,
-		opManager:  opManager,
-		clipboard:  make(map[string]string),
+		clipboard:  make(map[string]clipboardEntry),
+		save:       save,
+		logger:     logger,
 	}
 }
 
 func (foh *FileOperationHook) OnBeforeCommand(cmd eTypes.Command, e eTypes.Editor) {
-	// We don't need to do anything before command execution
+	// The deleted line is gone, and the cursor may have moved, once the
+	// command ran
+	if cmd.Name() != "delete_line" {
+		return
+	}
+
+	foh.deleting = ""
+	cursor, err := e.Buffer().GetPrimaryCursor()
+	if err != nil {
+		return
+	}
+	if content, err := e.Buffer().GetLine(cursor.GetPosition().Line()); err == nil {
+		foh.deleting = content
+	}
 }
 
 func (foh *FileOperationHook) OnAfterCommand(cmd eTypes.Command, e eTypes.Editor) {
 	switch cmd.Name() {
-	case "delete_line":
-		cursor, err := e.Buffer().GetPrimaryCursor()
-		if err != nil {
-			return
-		}
-
-		// Get the line that was deleted
-		line := cursor.GetPosition().Line()
-		content, err := e.Buffer().GetLine(line)
-		if err != nil {
-			return
-		}
+	case "write":
+		foh.save()
 
-		// Store in clipboard for potential move operations
-		foh.clipboard[content] = filepath.Join(foh.dirManager.CurrentPath(), content)
-
-		// Queue delete operation
-		foh.opManager.QueueOperation(operation.New(
-			types.Delete,
-			filepath.Join(foh.dirManager.CurrentPath(), content),
-			"",
-		))
-
-	case "change_line":
-		cursor, err := e.Buffer().GetPrimaryCursor()
-		if err != nil {
-			return
-		}
-
-		// Get the line before and after change
-		line := cursor.GetPosition().Line()
-		newContent, err := e.Buffer().GetLine(line)
-		if err != nil {
+	case "delete_line":
+		content := foh.deleting
+		foh.deleting = ""
+		if content == "" {
 			return
 		}
 
-		// Get original content from history
-		history := e.HistoryManager()
-		if len(history.UndoStack()) > 0 {
-			lastEntry := history.UndoStack()[len(history.UndoStack())-1]
-			oldContent := lastEntry.BeforeLines[line]
-
-			// Queue rename operation
-			foh.opManager.QueueOperation(operation.New(
-				types.Rename,
-				filepath.Join(foh.dirManager.CurrentPath(), oldContent),
-				filepath.Join(foh.dirManager.CurrentPath(), newContent),
-			))
-		}
-
-	case "paste":
-		cursor, err := e.Buffer().GetPrimaryCursor()
-		if err != nil {
-			return
+		// Entries of a read-only directory such as an archive can only be
+		// copied out, never deleted
+		foh.clipboard[content] = clipboardEntry{
+			path: filepath.Join(foh.dirManager.CurrentPath(), content),
+			copy: foh.dirManager.IsReadOnly(),
 		}
+	}
+}
 
-		// Get the line where paste occurred
-		line := cursor.GetPosition().Line()
-		content, err := e.Buffer().GetLine(line)
-		if err != nil {
-			return
-		}
+// Cut returns where the line was deleted from when it was, and whether the
+// entry has to be copied from there rather than moved. The line is
+// forgotten, a second paste creates a new entry.
+func (foh *FileOperationHook) Cut(line string) (string, bool, bool) {
+	entry, ok := foh.clipboard[line]
+	if !ok {
+		return "", false, false
+	}
+	delete(foh.clipboard, line)
+	return entry.path, entry.copy, true
+}
 
-		// If we have this content in clipboard, it's a move operation
-		if originalPath, exists := foh.clipboard[content]; exists {
-			foh.opManager.QueueOperation(operation.New(
-				types.Move,
-				originalPath,
-				foh.dirManager.CurrentPath(),
-			))
-			delete(foh.clipboard, content)
-		}
+// Keep forgets the line deleted from dir, it was put back there
+func (foh *FileOperationHook) Keep(dir, line string) {
+	if entry, ok := foh.clipboard[line]; ok && entry.path == filepath.Join(dir, line) {
+		delete(foh.clipboard, line)
 	}
 }
@@ -0,0 +1,46 @@
+package hook
+
+import (
+	"fmt"
+
+	eTypes "github.com/gunererd/grease/internal/editor/types"
+	types "github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// ScratchHook hands the buffer back to the open scratch on write.
+type ScratchHook struct {
+	scratch types.Scratch
+	logger  types.Logger
+}
+
+func NewScratchHook(scratch types.Scratch, logger types.Logger) *ScratchHook {
+	return &ScratchHook{
+		scratch: scratch,
+		logger:  logger,
+	}
+}
+
+func (sh *ScratchHook) OnBeforeCommand(cmd eTypes.Command, e eTypes.Editor) {
+	// We don't need to do anything before command execution
+}
+
+func (sh *ScratchHook) OnAfterCommand(cmd eTypes.Command, e eTypes.Editor) {
+	if cmd.Name() != "write" {
+		return
+	}
+
+	buf := e.Buffer()
+	lines := make([]string, 0, buf.LineCount())
+	for i := 0; i < buf.LineCount(); i++ {
+		line, err := buf.GetLine(i)
+		if err != nil {
+			return
+		}
+		lines = append(lines, line)
+	}
+
+	if err := sh.scratch.Write(lines); err != nil {
+		sh.logger.Printf("Failed to write %s: %v", sh.scratch.Name(), err)
+		e.SetMessage(fmt.Sprintf("Failed to write %s: %v", sh.scratch.Name(), err), true)
+	}
+}
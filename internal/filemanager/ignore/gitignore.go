@@ -0,0 +1,126 @@
+package ignore
+
+import (
+	"bufio"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Walk matches the entries of a walk of root against the base patterns
+// and the .gitignore files of the directories entered so far. It is not
+// safe for concurrent use, each walk creates its own.
+//
+// The .gitignore rules follow git for the common cases: comments, "!"
+// negation with the last matching rule winning, a trailing "/" for
+// directories only, and patterns containing a "/" anchored to the directory
+// of their .gitignore. A leading "**/" matches in any directory, other uses
+// of "**" are not supported.
+type Walk struct {
+	fs    types.FileSystem
+	root  string
+	base  types.IgnoreMatcher
+	rules []rule
+}
+
+type rule struct {
+	dir      string // slash separated, relative to root, "" for root itself
+	pattern  string
+	anchored bool
+	dirOnly  bool
+	negate   bool
+}
+
+func NewWalk(fs types.FileSystem, root string, base types.IgnoreMatcher) *Walk {
+	return &Walk{
+		fs:   fs,
+		root: root,
+		base: base,
+	}
+}
+
+// Enter reads the .gitignore of the directory rel below root, if it has
+// one. Its rules apply to the entries below rel matched afterwards.
+func (w *Walk) Enter(rel string) {
+	f, err := w.fs.Open(filepath.Join(w.root, rel, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	dir := filepath.ToSlash(rel)
+	if dir == "." {
+		dir = ""
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseRule(dir, scanner.Text()); ok {
+			w.rules = append(w.rules, r)
+		}
+	}
+}
+
+func parseRule(dir, line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	r := rule{dir: dir}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	line = strings.TrimPrefix(line, "**/")
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+	r.pattern = line
+	return r, true
+}
+
+func (w *Walk) Match(relPath string, isDir bool) bool {
+	if w.base.Match(relPath, isDir) {
+		return true
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	ignored := false
+	for _, r := range w.rules {
+		if r.match(relPath, isDir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func (r rule) match(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	sub := relPath
+	if r.dir != "" {
+		if !strings.HasPrefix(relPath, r.dir+"/") {
+			return false
+		}
+		sub = strings.TrimPrefix(relPath, r.dir+"/")
+	}
+
+	if r.anchored {
+		ok, _ := path.Match(r.pattern, sub)
+		return ok
+	}
+	ok, _ := path.Match(r.pattern, path.Base(sub))
+	return ok
+}
@@ -0,0 +1,44 @@
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// DefaultPatterns are always ignored by recursive walks
+var DefaultPatterns = []string{".git/"}
+
+// Matcher matches glob patterns against the base name and the slash
+// separated relative path of an entry. Patterns ending with "/" only match
+// directories.
+type Matcher struct {
+	patterns []string
+}
+
+func New(patterns []string) types.IgnoreMatcher {
+	return &Matcher{patterns: patterns}
+}
+
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	base := filepath.Base(relPath)
+
+	for _, pattern := range m.patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		if ok, _ := filepath.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type MatcherTestSuite struct {
+	suite.Suite
+}
+
+func (s *MatcherTestSuite) TestMatch() {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		matched  bool
+	}{
+		{name: "base name", patterns: []string{"*.log"}, path: "a/b/debug.log", matched: true},
+		{name: "relative path", patterns: []string{"a/*.log"}, path: "a/debug.log", matched: true},
+		{name: "no match", patterns: []string{"*.log"}, path: "a/debug.txt", matched: false},
+		{name: "directory pattern on directory", patterns: []string{".git/"}, path: ".git", isDir: true, matched: true},
+		{name: "directory pattern on file", patterns: []string{".git/"}, path: ".git", matched: false},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.matched, New(tt.patterns).Match(tt.path, tt.isDir))
+		})
+	}
+}
+
+func TestMatcherSuite(t *testing.T) {
+	suite.Run(t, new(MatcherTestSuite))
+}
+
+type WalkTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *WalkTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/repo/web/dist", 0755))
+	s.writeFile("/repo/.gitignore", "# build output\nnode_modules/\n*.log\n!keep.log\n/out\n")
+	s.writeFile("/repo/web/.gitignore", "dist/\nconfig/*.local\n")
+}
+
+func (s *WalkTestSuite) writeFile(path, content string) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(content))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+func (s *WalkTestSuite) TestMatch() {
+	w := NewWalk(s.fs, "/repo", New(DefaultPatterns))
+	w.Enter("")
+	w.Enter("web")
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		matched bool
+	}{
+		{path: ".git", isDir: true, matched: true},
+		{path: "node_modules", isDir: true, matched: true},
+		{path: "web/node_modules", isDir: true, matched: true},
+		{path: "node_modules", matched: false},
+		{path: "debug.log", matched: true},
+		{path: "web/debug.log", matched: true},
+		{path: "keep.log", matched: false},
+		{path: "out", matched: true},
+		{path: "web/out", matched: false},
+		{path: "web/dist", isDir: true, matched: true},
+		{path: "dist", isDir: true, matched: false},
+		{path: "web/config/app.local", matched: true},
+		{path: "web/app.local", matched: false},
+		{path: "main.go", matched: false},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.path, func() {
+			s.Equal(tt.matched, w.Match(tt.path, tt.isDir))
+		})
+	}
+}
+
+func (s *WalkTestSuite) TestNotEntered() {
+	w := NewWalk(s.fs, "/repo", New(nil))
+	w.Enter("")
+
+	s.False(w.Match("web/dist", true))
+}
+
+func TestWalkSuite(t *testing.T) {
+	suite.Run(t, new(WalkTestSuite))
+}
@@ -4,16 +4,42 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
 
 	eTypes "github.com/gunererd/grease/internal/editor/types"
+	"github.com/gunererd/grease/internal/filemanager/audit"
+	"github.com/gunererd/grease/internal/filemanager/bookmark"
 	"github.com/gunererd/grease/internal/filemanager/directory"
+	"github.com/gunererd/grease/internal/filemanager/du"
+	"github.com/gunererd/grease/internal/filemanager/finder"
+	"github.com/gunererd/grease/internal/filemanager/frecency"
+	"github.com/gunererd/grease/internal/filemanager/grep"
+	"github.com/gunererd/grease/internal/filemanager/ignore"
+	"github.com/gunererd/grease/internal/filemanager/lscolors"
+	"github.com/gunererd/grease/internal/filemanager/navigation"
 	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/picker"
+	"github.com/gunererd/grease/internal/filemanager/templates"
 	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
 	"github.com/gunererd/grease/internal/filemanager/view"
+	"github.com/gunererd/grease/internal/filemanager/xdg"
 )
 
 type options struct {
-	LogFile string
+	LogFile      string
+	BookmarkFile string
+	FrecencyFile string
+	TemplateDir  string
+	Ignore       []string
+	FileSystem   types.FileSystem
+	OnFailure    types.FailurePolicy
+	OnConflict   types.ConflictResolution
+	LSColors     string
+	AuditLog     string
+	Pick         bool
+	PickRoot     string
+	PickOnly     types.PickKind
 }
 
 type Option func(*options)
@@ -24,32 +50,180 @@ func WithLog(filename string) Option {
 	}
 }
 
-func Initialize(editor eTypes.Editor, opts ...Option) (types.FileManager, error) {
-	options := options{}
+// WithBookmarkFile overrides where bookmarks are persisted
+func WithBookmarkFile(filename string) Option {
+	return func(o *options) {
+		o.BookmarkFile = filename
+	}
+}
+
+// WithFrecencyFile overrides where visited directories are ranked for :z.
+// An empty name turns the ranking off.
+func WithFrecencyFile(filename string) Option {
+	return func(o *options) {
+		o.FrecencyFile = filename
+	}
+}
+
+// WithTemplateDir sets the directory new files are seeded from, see
+// templates.Dir. An empty name creates empty files.
+func WithTemplateDir(dir string) Option {
+	return func(o *options) {
+		o.TemplateDir = dir
+	}
+}
+
+// WithIgnore adds glob patterns that recursive walks such as the finder
+// skip. A trailing "/" restricts a pattern to directories.
+func WithIgnore(patterns ...string) Option {
+	return func(o *options) {
+		o.Ignore = append(o.Ignore, patterns...)
+	}
+}
+
+// WithFileSystem replaces the local disk with archives mounted over it as
+// the storage that is browsed and modified, e.g. with vfs.NewMemory() in
+// tests
+func WithFileSystem(fs types.FileSystem) Option {
+	return func(o *options) {
+		o.FileSystem = fs
+	}
+}
+
+// WithFailurePolicy sets what happens to the remaining operations of a save
+// once one fails. The default is types.StopOnFailure.
+func WithFailurePolicy(policy types.FailurePolicy) Option {
+	return func(o *options) {
+		o.OnFailure = policy
+	}
+}
+
+// WithConflictResolution sets how an operation whose target already exists
+// is handled without asking, e.g. for non-interactive runs. The default
+// types.ConflictAsk prompts for each conflict.
+func WithConflictResolution(resolution types.ConflictResolution) Option {
+	return func(o *options) {
+		o.OnConflict = resolution
+	}
+}
+
+// WithLSColors sets the LS_COLORS value entries are coloured by. The
+// default is the environment variable, or lscolors.DefaultSpec when that is
+// unset.
+func WithLSColors(spec string) Option {
+	return func(o *options) {
+		o.LSColors = spec
+	}
+}
+
+// WithAuditLog sets the file executed operations are recorded in, one JSON
+// object per line. An empty name turns the record off.
+func WithAuditLog(filename string) Option {
+	return func(o *options) {
+		o.AuditLog = filename
+	}
+}
+
+// WithPicker runs grease as a file chooser whose picked entries are read
+// from FileManager.Picker once it quits. Only entries of kind only can be
+// picked, and when root is not empty navigation stays within it.
+func WithPicker(root string, only types.PickKind) Option {
+	return func(o *options) {
+		o.Pick = true
+		o.PickRoot = root
+		o.PickOnly = only
+	}
+}
+
+func newOptions(opts []Option) options {
+	options := options{
+		BookmarkFile: xdg.DataFile("bookmarks"),
+		FrecencyFile: xdg.DataFile("frecency"),
+		TemplateDir:  xdg.ConfigFile("templates"),
+		Ignore:       append([]string{}, ignore.DefaultPatterns...),
+		FileSystem:   vfs.NewMount(vfs.NewOS()),
+		LSColors:     os.Getenv("LS_COLORS"),
+		AuditLog:     xdg.DataFile("oplog.jsonl"),
+	}
 
 	for _, opt := range opts {
 		opt(&options)
 	}
+	return options
+}
+
+func (o options) logger() (types.Logger, error) {
+	if o.LogFile == "" {
+		return log.New(os.Stderr, "FILEMANAGER: ", log.Ldate|log.Ltime|log.Lmicroseconds), nil
+	}
 
-	var logger types.Logger
-	if options.LogFile != "" {
-		fileLogger, err := NewFileLogger(options.LogFile, "FILEMANAGER")
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize logger: %w", err)
-		}
-		logger = fileLogger
-	} else {
-		logger = log.New(os.Stderr, "FILEMANAGER: ", log.Ldate|log.Ltime|log.Lmicroseconds)
+	fileLogger, err := NewFileLogger(o.LogFile, "FILEMANAGER")
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
+	return fileLogger, nil
+}
 
-	dirManager := directory.NewDirectoryManager("", logger)
-	opManager := operation.NewOperationManager(dirManager, logger)
-	view := view.New(editor)
+func (o options) auditLog() types.AuditLog {
+	if o.AuditLog == "" {
+		return nil
+	}
+	return audit.New(o.AuditLog, audit.DefaultMaxSize, audit.DefaultKeep)
+}
+
+func (o options) templates() types.Templates {
+	if o.TemplateDir == "" {
+		return nil
+	}
+	return templates.New(o.TemplateDir)
+}
+
+func (o options) frecency() types.Frecency {
+	if o.FrecencyFile == "" {
+		return nil
+	}
+	return frecency.New(o.FrecencyFile, o.FileSystem)
+}
+
+func Initialize(editor eTypes.Editor, opts ...Option) (types.FileManager, error) {
+	options := newOptions(opts)
+	logger, err := options.logger()
+	if err != nil {
+		return nil, err
+	}
+
+	dirManager := directory.NewDirectoryManager("", options.FileSystem, logger)
+	opManager := operation.NewOperationManager(dirManager, options.FileSystem, options.templates(), options.OnFailure, options.OnConflict, options.auditLog(), logger)
+	bookmarks := bookmark.NewBookmarkManager(options.BookmarkFile, logger)
+	history := navigation.NewHistory(100)
+	ignored := ignore.New(options.Ignore)
+	finder := finder.New(options.FileSystem, ignored, logger)
+	view := view.New(editor, finder)
+
+	var pick types.Picker
+	if options.Pick {
+		root := options.PickRoot
+		if root != "" {
+			if root, err = resolvePath(options.FileSystem, root); err != nil {
+				return nil, fmt.Errorf("invalid picker root: %w", err)
+			}
+		}
+		pick = picker.New(root, options.PickOnly)
+	}
 
 	fm := New(
+		options.FileSystem,
 		dirManager,
 		opManager,
+		bookmarks,
+		history,
+		finder,
+		options.frecency(),
 		view,
+		lscolors.New(options.LSColors),
+		du.New(options.FileSystem, runtime.NumCPU()),
+		grep.New(options.FileSystem, ignored, runtime.NumCPU(), logger),
+		pick,
 		editor,
 		logger,
 	)
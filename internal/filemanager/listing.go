@@ -0,0 +1,123 @@
+package filemanager
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/reconcile"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// save queues the edits of the listing and applies everything queued
+func (fm *Filemanager) save() {
+	if err := fm.QueueEdits(); err != nil {
+		fm.editor.SetMessage(err.Error(), true)
+		return
+	}
+	fm.applyOperations()
+}
+
+// QueueEdits queues the operations that turn the current directory into
+// the edited listing, the way grease apply does. Added lines that were cut
+// from another directory move the entry here instead of creating it. The
+// listing then counts as saved, so nothing is queued twice.
+func (fm *Filemanager) QueueEdits() error {
+	if fm.scratch != nil || fm.listed == nil {
+		return nil
+	}
+
+	dir := fm.dirManager.CurrentPath()
+	lines := fm.lines()
+	if fm.fs.ReadOnly(dir) {
+		fm.listed = lines
+		return nil
+	}
+
+	ops, err := reconcile.Plan(dir, fm.listed, lines)
+	if err != nil {
+		return fmt.Errorf("cannot save the listing: %w", err)
+	}
+	for _, line := range lines {
+		fm.opHook.Keep(dir, line)
+	}
+
+	for _, op := range ops {
+		if op.Type() == types.Create {
+			op = fm.pasted(op)
+		}
+		if !fm.isQueued(op) {
+			fm.opManager.QueueOperation(op)
+		}
+	}
+	fm.listed = lines
+	return nil
+}
+
+// pasted turns the creation of an entry that was cut from another
+// directory into moving it here, dropping its queued deletion
+func (fm *Filemanager) pasted(create types.Operation) types.Operation {
+	line := filepath.Base(create.Source())
+	if strings.HasSuffix(create.Source(), "/") {
+		line += "/"
+	}
+	source, copy, ok := fm.opHook.Cut(line)
+	if !ok {
+		return create
+	}
+
+	if copy {
+		return operation.New(types.Copy, source, filepath.Dir(filepath.Clean(create.Source())))
+	}
+	for _, op := range fm.opManager.GetPendingOperations() {
+		if op.Type() == types.Delete && op.Source() == source {
+			fm.opManager.RemoveOperation(op)
+			break
+		}
+	}
+	return operation.New(types.Move, source, filepath.Dir(filepath.Clean(create.Source())))
+}
+
+func (fm *Filemanager) isQueued(op types.Operation) bool {
+	for _, queued := range fm.opManager.GetPendingOperations() {
+		if queued.Type() == op.Type() && queued.Source() == op.Source() && queued.Target() == op.Target() {
+			return true
+		}
+	}
+	return false
+}
+
+// unsaved reports whether there are queued operations or edits of the
+// listing that are not queued yet
+func (fm *Filemanager) unsaved() bool {
+	if len(fm.opManager.GetPendingOperations()) > 0 {
+		return true
+	}
+	if fm.scratch != nil || fm.listed == nil {
+		return false
+	}
+
+	lines := fm.lines()
+	if len(lines) != len(fm.listed) {
+		return true
+	}
+	for i := range lines {
+		if lines[i] != fm.listed[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// lines returns the non-empty lines of the buffer
+func (fm *Filemanager) lines() []string {
+	buf := fm.editor.Buffer()
+	lines := make([]string, 0, buf.LineCount())
+	for i := 0; i < buf.LineCount(); i++ {
+		if line, err := buf.GetLine(i); err == nil && line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
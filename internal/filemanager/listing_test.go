@@ -0,0 +1,180 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/editor"
+	"github.com/gunererd/grease/internal/filemanager/gitstatus"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type ListingTestSuite struct {
+	suite.Suite
+	fs   types.FileSystem
+	fm   types.FileManager
+	opts []Option
+}
+
+func (s *ListingTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/work/docs", 0755))
+	s.Require().NoError(s.fs.MkdirAll("/other", 0755))
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		f, err := s.fs.Create("/work/"+name, 0644)
+		s.Require().NoError(err)
+		s.Require().NoError(f.Close())
+	}
+
+	state := s.T().TempDir()
+	s.opts = []Option{
+		WithFileSystem(s.fs),
+		WithLog(filepath.Join(state, "log")),
+		WithBookmarkFile(filepath.Join(state, "bookmarks")),
+		WithFrecencyFile(filepath.Join(state, "frecency")),
+	}
+
+	e, err := editor.Initialize()
+	s.Require().NoError(err)
+	s.fm, err = Initialize(e, s.opts...)
+	s.Require().NoError(err)
+	s.Require().NoError(s.fm.LoadDirectory("/work"))
+}
+
+func (s *ListingTestSuite) edit(lines []string) {
+	buf := s.fm.Editor().Buffer()
+	s.Require().NoError(buf.LoadFromReader(strings.NewReader(strings.Join(lines, "\n"))))
+}
+
+func (s *ListingTestSuite) pending() []string {
+	var ops []string
+	for _, op := range s.fm.OperationManager().GetPendingOperations() {
+		ops = append(ops, operation.Describe(op))
+	}
+	return ops
+}
+
+// A save reconciles the listing the same way grease apply does
+func (s *ListingTestSuite) TestSaveMatchesApply() {
+	tests := []struct {
+		name   string
+		edited []string
+		err    bool
+	}{
+		{name: "unchanged", edited: []string{"docs/", "a.txt", "b.txt", "c.txt"}},
+		{name: "reordered", edited: []string{"c.txt", "a.txt", "docs/", "b.txt"}},
+		{name: "renamed", edited: []string{"docs/", "a.txt", "notes.txt", "c.txt"}},
+		{name: "deleted and created", edited: []string{"docs/", "new/", "a.txt", "c.txt", "d.txt"}},
+		{name: "deleted and pasted back", edited: []string{"docs/", "b.txt", "a.txt", "c.txt"}},
+		{name: "duplicated", edited: []string{"docs/", "a.txt", "a.txt", "b.txt", "c.txt"}, err: true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+
+			planned, planErr := Plan("/work", strings.NewReader(strings.Join(tt.edited, "\n")), s.opts...)
+			var expected []string
+			for _, op := range planned {
+				expected = append(expected, operation.Describe(op))
+			}
+
+			s.edit(tt.edited)
+			err := s.fm.QueueEdits()
+			if tt.err {
+				s.Error(planErr)
+				s.Error(err)
+				return
+			}
+			s.Require().NoError(planErr)
+			s.Require().NoError(err)
+			s.Equal(expected, s.pending())
+		})
+	}
+}
+
+func (s *ListingTestSuite) TestQueuedOnce() {
+	s.edit([]string{"docs/", "a.txt", "c.txt"})
+	s.Require().NoError(s.fm.QueueEdits())
+	s.Require().NoError(s.fm.QueueEdits())
+	s.Equal([]string{"delete /work/b.txt"}, s.pending())
+}
+
+func (s *ListingTestSuite) TestPasteIntoOtherDirectoryMoves() {
+	buf := s.fm.Editor().Buffer()
+	cursor, err := buf.GetPrimaryCursor()
+	s.Require().NoError(err)
+	s.Require().NoError(buf.MoveCursor(cursor.ID(), 2, 0))
+	for _, key := range "dd" {
+		s.fm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	}
+	s.Require().Equal("docs/\na.txt\nc.txt", buf.Get())
+
+	s.Require().NoError(s.fm.LoadDirectory("/other"))
+	s.Equal([]string{"delete /work/b.txt"}, s.pending())
+
+	s.edit([]string{"b.txt"})
+	s.Require().NoError(s.fm.QueueEdits())
+	s.Equal([]string{"move /work/b.txt -> /other/"}, s.pending())
+}
+
+func (s *ListingTestSuite) keys(keys string) {
+	for _, key := range keys {
+		s.fm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	}
+}
+
+func (s *ListingTestSuite) command(command string) {
+	s.keys(":" + command)
+	s.fm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+// :s only edits the lines, saving renames the entries
+func (s *ListingTestSuite) TestSubstitute() {
+	s.command(`%s/\.txt$/.md/`)
+	s.Equal("docs/\na.md\nb.md\nc.md", s.fm.Editor().Buffer().Get())
+	s.Empty(s.pending())
+
+	s.Require().NoError(s.fm.QueueEdits())
+	s.Equal([]string{
+		"rename /work/a.txt -> a.md",
+		"rename /work/b.txt -> b.md",
+		"rename /work/c.txt -> c.md",
+	}, s.pending())
+}
+
+func (s *ListingTestSuite) TestSubstituteUndone() {
+	s.command(`%s/\.txt$/.md/`)
+	s.keys("u")
+	s.Equal("docs/\na.txt\nb.txt\nc.txt", s.fm.Editor().Buffer().Get())
+
+	s.Require().NoError(s.fm.QueueEdits())
+	s.Empty(s.pending())
+}
+
+// Updates that change nothing shown, such as spinner ticks, keep the highlights
+func (s *ListingTestSuite) TestDecoratesOnChange() {
+	fm := s.fm.(*Filemanager)
+	fm.Update(gitStatusMsg{dir: "/work", statuses: map[string]gitstatus.Status{"a.txt": gitstatus.Modified}})
+	decorated := append([]int(nil), fm.decorations...)
+	s.Len(decorated, 2) // the colour of docs/ and the status of a.txt
+
+	fm.Update(struct{}{})
+	s.Equal(decorated, fm.decorations)
+
+	s.edit([]string{"a.txt", "docs/"})
+	fm.Update(struct{}{})
+	s.NotEqual(decorated, fm.decorations)
+
+	fm.Update(gitStatusMsg{dir: "/work", statuses: map[string]gitstatus.Status{}})
+	s.Len(fm.decorations, 1)
+}
+
+func TestListingSuite(t *testing.T) {
+	suite.Run(t, new(ListingTestSuite))
+}
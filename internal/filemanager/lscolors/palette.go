@@ -0,0 +1,162 @@
+package lscolors
+
+import (
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// DefaultSpec is used when LS_COLORS is unset. It follows the defaults of
+// dircolors.
+const DefaultSpec = "di=01;34:ln=01;36:pi=40;33:so=01;35:do=01;35:bd=40;33;01:cd=40;33;01:" +
+	"or=40;31;01:su=37;41:sg=30;43:tw=30;42:ow=34;42:st=37;44:ex=01;32:" +
+	"*.tar=01;31:*.tgz=01;31:*.gz=01;31:*.bz2=01;31:*.xz=01;31:*.zst=01;31:" +
+	"*.zip=01;31:*.7z=01;31:*.rar=01;31:*.deb=01;31:*.rpm=01;31:*.jar=01;31:" +
+	"*.jpg=01;35:*.jpeg=01;35:*.png=01;35:*.gif=01;35:*.bmp=01;35:*.svg=01;35:" +
+	"*.webp=01;35:*.mp4=01;35:*.mkv=01;35:*.webm=01;35:*.mov=01;35:*.avi=01;35:" +
+	"*.mp3=00;36:*.flac=00;36:*.ogg=00;36:*.wav=00;36:*.m4a=00;36"
+
+// Palette maps file types and name suffixes to SGR sequences
+type Palette struct {
+	kinds    map[string]string // two letter keys such as di or ex
+	suffixes []suffix          // longest first
+}
+
+type suffix struct {
+	text string
+	sgr  string
+}
+
+// New parses an LS_COLORS value, falling back to DefaultSpec when it is
+// empty
+func New(spec string) types.EntryColors {
+	if spec == "" {
+		spec = DefaultSpec
+	}
+	return Parse(spec)
+}
+
+// Parse reads the colon separated key=value pairs of an LS_COLORS value.
+// Keys starting with "*" match the end of a file name, ignoring case.
+// Malformed pairs are skipped.
+func Parse(spec string) *Palette {
+	p := &Palette{kinds: make(map[string]string)}
+
+	for _, pair := range strings.Split(spec, ":") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			continue
+		}
+
+		if strings.HasPrefix(key, "*") {
+			p.suffixes = append(p.suffixes, suffix{text: strings.ToLower(key[1:]), sgr: value})
+			continue
+		}
+		p.kinds[key] = value
+	}
+
+	// A later pair for the same suffix wins, and longer suffixes are tried
+	// first so that ".tar.gz" beats ".gz"
+	sort.SliceStable(p.suffixes, func(i, j int) bool {
+		return len(p.suffixes[i].text) > len(p.suffixes[j].text)
+	})
+	for i := 0; i < len(p.suffixes); i++ {
+		for j := i + 1; j < len(p.suffixes); j++ {
+			if p.suffixes[j].text == p.suffixes[i].text {
+				p.suffixes[i].sgr = p.suffixes[j].sgr
+			}
+		}
+	}
+
+	return p
+}
+
+func (p *Palette) Color(fsys types.FileSystem, path string) string {
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return p.kind("mi")
+	}
+	return p.classify(fsys, path, info)
+}
+
+func (p *Palette) classify(fsys types.FileSystem, path string, info fs.FileInfo) string {
+	mode := info.Mode()
+
+	switch {
+	case mode&fs.ModeSymlink != 0:
+		// ln=target draws a link like what it points to
+		follow := p.kinds["ln"] == "target"
+		target, err := fsys.Stat(path)
+		switch {
+		case err != nil && follow:
+			return p.kind("or")
+		case err != nil:
+			return p.kind("or", "ln")
+		case follow:
+			return p.classify(fsys, path, target)
+		}
+		return p.kind("ln")
+
+	case mode.IsDir():
+		sticky := mode&fs.ModeSticky != 0
+		otherWritable := mode.Perm()&0o002 != 0
+		switch {
+		case sticky && otherWritable:
+			return p.kind("tw", "ow", "di")
+		case otherWritable:
+			return p.kind("ow", "di")
+		case sticky:
+			return p.kind("st", "di")
+		}
+		return p.kind("di")
+
+	case mode&fs.ModeNamedPipe != 0:
+		return p.kind("pi")
+	case mode&fs.ModeSocket != 0:
+		return p.kind("so")
+	case mode&fs.ModeCharDevice != 0:
+		return p.kind("cd")
+	case mode&fs.ModeDevice != 0:
+		return p.kind("bd")
+
+	case mode&fs.ModeSetuid != 0 && p.has("su"):
+		return p.kind("su")
+	case mode&fs.ModeSetgid != 0 && p.has("sg"):
+		return p.kind("sg")
+	case mode.Perm()&0o111 != 0 && p.has("ex"):
+		return p.kind("ex")
+	}
+
+	// Suffixes only apply to plain files, as with ls
+	name := strings.ToLower(info.Name())
+	for _, s := range p.suffixes {
+		if strings.HasSuffix(name, s.text) {
+			return plain(s.sgr)
+		}
+	}
+	return p.kind("fi")
+}
+
+func (p *Palette) has(key string) bool {
+	return plain(p.kinds[key]) != ""
+}
+
+// kind returns the sequence of the first of keys that is set
+func (p *Palette) kind(keys ...string) string {
+	for _, key := range keys {
+		if sgr := plain(p.kinds[key]); sgr != "" {
+			return sgr
+		}
+	}
+	return ""
+}
+
+// plain turns sequences that only reset, such as "00", into ""
+func plain(sgr string) string {
+	if strings.Trim(sgr, "0;") == "" {
+		return ""
+	}
+	return sgr
+}
@@ -0,0 +1,101 @@
+package lscolors
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type PaletteTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *PaletteTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/root/dir", 0o755))
+
+	for path, perm := range map[string]fs.FileMode{
+		"/root/plain.txt":   0o644,
+		"/root/run.sh":      0o755,
+		"/root/photo.PNG":   0o644,
+		"/root/pack.tar.gz": 0o644,
+		"/root/run.tar.gz":  0o755,
+	} {
+		w, err := s.fs.Create(path, perm)
+		s.Require().NoError(err)
+		s.Require().NoError(w.Close())
+	}
+
+	s.Require().NoError(s.fs.Symlink("/root/dir", "/root/link"))
+	s.Require().NoError(s.fs.Symlink("/root/missing", "/root/broken"))
+}
+
+func (s *PaletteTestSuite) TestColor() {
+	tests := []struct {
+		name     string
+		spec     string
+		path     string
+		expected string
+	}{
+		{name: "directory", spec: "di=01;34", path: "/root/dir", expected: "01;34"},
+		{name: "plain file", spec: "di=01;34", path: "/root/plain.txt", expected: ""},
+		{name: "plain file with fi", spec: "fi=37", path: "/root/plain.txt", expected: "37"},
+		{name: "executable", spec: "ex=01;32", path: "/root/run.sh", expected: "01;32"},
+		{name: "suffix ignores case", spec: "*.png=35", path: "/root/photo.PNG", expected: "35"},
+		{name: "longest suffix wins", spec: "*.gz=31:*.tar.gz=33", path: "/root/pack.tar.gz", expected: "33"},
+		{name: "later suffix wins", spec: "*.gz=31:*.gz=32", path: "/root/pack.tar.gz", expected: "32"},
+		{name: "executable beats suffix", spec: "ex=32:*.gz=31", path: "/root/run.tar.gz", expected: "32"},
+		{name: "symlink", spec: "ln=36:di=34", path: "/root/link", expected: "36"},
+		{name: "symlink as target", spec: "ln=target:di=34", path: "/root/link", expected: "34"},
+		{name: "broken symlink", spec: "ln=36:or=31", path: "/root/broken", expected: "31"},
+		{name: "broken symlink without or", spec: "ln=36", path: "/root/broken", expected: "36"},
+		{name: "missing entry", spec: "mi=05", path: "/root/nothing", expected: "05"},
+		{name: "reset only is plain", spec: "di=00", path: "/root/dir", expected: ""},
+		{name: "malformed pairs are skipped", spec: "bogus:=1:di=34", path: "/root/dir", expected: "34"},
+		{name: "default palette", spec: "", path: "/root/dir", expected: "01;34"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.expected, New(tt.spec).Color(s.fs, tt.path))
+		})
+	}
+}
+
+func (s *PaletteTestSuite) TestStyle() {
+	none := lipgloss.NoColor{}
+	tests := []struct {
+		name string
+		sgr  string
+		fg   lipgloss.TerminalColor
+		bg   lipgloss.TerminalColor
+		bold bool
+	}{
+		{name: "bold blue", sgr: "01;34", fg: lipgloss.Color("4"), bg: none, bold: true},
+		{name: "bright foreground", sgr: "92", fg: lipgloss.Color("10"), bg: none},
+		{name: "background", sgr: "30;42", fg: lipgloss.Color("0"), bg: lipgloss.Color("2")},
+		{name: "256 colours", sgr: "38;5;208", fg: lipgloss.Color("208"), bg: none},
+		{name: "true colour", sgr: "48;2;255;0;16", fg: none, bg: lipgloss.Color("#ff0010")},
+		{name: "reset clears earlier codes", sgr: "01;0;31", fg: lipgloss.Color("1"), bg: none},
+		{name: "truncated extended colour", sgr: "38;5", fg: none, bg: none},
+		{name: "unknown codes are ignored", sgr: "x;58;34", fg: lipgloss.Color("4"), bg: none},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			style := Style(tt.sgr)
+			s.Equal(tt.fg, style.GetForeground())
+			s.Equal(tt.bg, style.GetBackground())
+			s.Equal(tt.bold, style.GetBold())
+		})
+	}
+}
+
+func TestPaletteSuite(t *testing.T) {
+	suite.Run(t, new(PaletteTestSuite))
+}
@@ -0,0 +1,97 @@
+package lscolors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Style turns an SGR sequence such as "01;38;5;208" into a lipgloss style.
+// Codes it does not know are ignored.
+func Style(sgr string) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	codes := strings.Split(sgr, ";")
+
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			style = lipgloss.NewStyle()
+		case code == 1:
+			style = style.Bold(true)
+		case code == 2:
+			style = style.Faint(true)
+		case code == 3:
+			style = style.Italic(true)
+		case code == 4:
+			style = style.Underline(true)
+		case code == 5:
+			style = style.Blink(true)
+		case code == 7:
+			style = style.Reverse(true)
+		case code == 9:
+			style = style.Strikethrough(true)
+		case code >= 30 && code <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(code - 30)))
+		case code >= 90 && code <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(code - 90 + 8)))
+		case code >= 40 && code <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(code - 40)))
+		case code >= 100 && code <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(code - 100 + 8)))
+		case code == 38 || code == 48:
+			color, n := extendedColor(codes[i+1:])
+			i += n
+			if color == "" {
+				continue
+			}
+			if code == 38 {
+				style = style.Foreground(lipgloss.Color(color))
+			} else {
+				style = style.Background(lipgloss.Color(color))
+			}
+		}
+	}
+
+	return style
+}
+
+// extendedColor reads the 256 colour ("5;n") or true colour ("2;r;g;b")
+// form following a 38 or 48 code. It returns the colour and how many codes
+// it used.
+func extendedColor(codes []string) (string, int) {
+	if len(codes) == 0 {
+		return "", 0
+	}
+
+	switch codes[0] {
+	case "5":
+		if len(codes) < 2 {
+			return "", len(codes)
+		}
+		if _, err := strconv.Atoi(codes[1]); err != nil {
+			return "", 2
+		}
+		return codes[1], 2
+	case "2":
+		if len(codes) < 4 {
+			return "", len(codes)
+		}
+		var rgb [3]int
+		for j := range rgb {
+			v, err := strconv.Atoi(codes[j+1])
+			if err != nil || v < 0 || v > 255 {
+				return "", 4
+			}
+			rgb[j] = v
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), 4
+	}
+	return "", 1
+}
@@ -0,0 +1,82 @@
+package navigation
+
+import "github.com/gunererd/grease/internal/filemanager/types"
+
+type History struct {
+	jumps   []string
+	index   int
+	pending int // entry Back or Forward returned until it is visited, -1 if none
+	cursors map[string]string
+	limit   int
+}
+
+func NewHistory(limit int) types.NavigationHistory {
+	return &History{
+		jumps:   make([]string, 0),
+		index:   -1,
+		pending: -1,
+		cursors: make(map[string]string),
+		limit:   limit,
+	}
+}
+
+// Push records a visit to path. Visiting the entry Back or Forward just
+// returned moves there and keeps the rest of the list intact, any other
+// path drops the forward history like a browser does.
+func (h *History) Push(path string) {
+	pending := h.pending
+	h.pending = -1
+	if pending >= 0 && h.jumps[pending] == path {
+		h.index = pending
+		return
+	}
+	if h.index >= 0 && h.jumps[h.index] == path {
+		return
+	}
+
+	h.jumps = append(h.jumps[:h.index+1], path)
+	if len(h.jumps) > h.limit {
+		h.jumps = h.jumps[len(h.jumps)-h.limit:]
+	}
+	h.index = len(h.jumps) - 1
+}
+
+// Back returns the entry before the current one. The history only moves
+// there once it is visited, so an entry that failed to load is stepped
+// over by the next call.
+func (h *History) Back() (string, bool) {
+	from := h.from()
+	if from <= 0 {
+		return "", false
+	}
+	h.pending = from - 1
+	return h.jumps[h.pending], true
+}
+
+// Forward is Back in the other direction
+func (h *History) Forward() (string, bool) {
+	from := h.from()
+	if from >= len(h.jumps)-1 {
+		return "", false
+	}
+	h.pending = from + 1
+	return h.jumps[h.pending], true
+}
+
+// from is where Back and Forward step from: the entry returned last if it
+// was not visited, the current one otherwise
+func (h *History) from() int {
+	if h.pending >= 0 {
+		return h.pending
+	}
+	return h.index
+}
+
+func (h *History) SetCursor(dir string, entry string) {
+	h.cursors[dir] = entry
+}
+
+func (h *History) Cursor(dir string) (string, bool) {
+	entry, ok := h.cursors[dir]
+	return entry, ok
+}
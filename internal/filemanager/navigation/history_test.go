@@ -0,0 +1,74 @@
+package navigation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HistoryTestSuite struct {
+	suite.Suite
+	history *History
+}
+
+func (s *HistoryTestSuite) SetupTest() {
+	s.history = NewHistory(100).(*History)
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		s.history.Push(path)
+	}
+}
+
+// jump returns where Back or Forward lead and visits it unless it failed
+// to load
+func (s *HistoryTestSuite) jump(next func() (string, bool), loads bool) string {
+	path, ok := next()
+	s.Require().True(ok)
+	if loads {
+		s.history.Push(path)
+	}
+	return path
+}
+
+func (s *HistoryTestSuite) TestBackAndForward() {
+	s.Equal("/c", s.jump(s.history.Back, true))
+	s.Equal("/b", s.jump(s.history.Back, true))
+	s.Equal("/c", s.jump(s.history.Forward, true))
+	s.Equal("/d", s.jump(s.history.Forward, true))
+
+	_, ok := s.history.Forward()
+	s.False(ok)
+}
+
+func (s *HistoryTestSuite) TestFailedLoad() {
+	// /c was deleted, the next jump steps over it
+	s.Equal("/c", s.jump(s.history.Back, false))
+	s.Equal("/b", s.jump(s.history.Back, true))
+
+	// Going forward from /b tries /c again
+	s.Equal("/c", s.jump(s.history.Forward, false))
+	s.Equal("/d", s.jump(s.history.Forward, true))
+	s.Equal([]string{"/a", "/b", "/c", "/d"}, s.history.jumps)
+}
+
+func (s *HistoryTestSuite) TestFailedLoadKeepsPosition() {
+	s.Equal("/c", s.jump(s.history.Back, false))
+
+	// Visiting another directory drops the history after the current one,
+	// which never moved
+	s.history.Push("/e")
+	s.Equal([]string{"/a", "/b", "/c", "/d", "/e"}, s.history.jumps)
+	s.Equal("/d", s.jump(s.history.Back, true))
+}
+
+func (s *HistoryTestSuite) TestVisitDropsForward() {
+	s.Equal("/c", s.jump(s.history.Back, true))
+	s.history.Push("/e")
+
+	s.Equal([]string{"/a", "/b", "/c", "/e"}, s.history.jumps)
+	_, ok := s.history.Forward()
+	s.False(ok)
+}
+
+func TestHistorySuite(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
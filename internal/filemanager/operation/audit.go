@@ -0,0 +1,61 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// auditedExecutor records every operation in an audit log once it was
+// executed, whatever its outcome. The target recorded is the path the
+// operation created, such as the free name picked for a conflict or the
+// entry inside the directory moved into.
+type auditedExecutor struct {
+	*Executor
+	log    types.AuditLog
+	cwd    func(types.Operation) (string, bool) // directory shown when op was queued
+	logger types.Logger
+}
+
+func (a *auditedExecutor) Execute(ctx context.Context, op types.Operation, progress types.ProgressFunc, resolve types.ConflictResolver) error {
+	target, err := a.Executor.execute(ctx, op, progress, resolve)
+	if target == "" {
+		target = destination(op)
+	}
+
+	status := types.OperationSucceeded
+	switch {
+	case errors.Is(err, types.ErrSkipped):
+		status = types.OperationSkipped
+	case err != nil:
+		status = types.OperationFailed
+	}
+
+	entry := types.AuditEntry{
+		Time:   time.Now(),
+		Type:   op.Type().String(),
+		Source: strings.Join(op.Sources(), ", "),
+		Target: target,
+		Result: status.String(),
+	}
+	if status == types.OperationFailed {
+		entry.Error = err.Error()
+	}
+	// The directory the operation was made in, not where grease started.
+	// Operations that were never queued fall back to that of their source.
+	if dir, ok := a.cwd(op); ok {
+		entry.Cwd = dir
+	} else if sources := op.Sources(); len(sources) > 0 {
+		entry.Cwd = filepath.Dir(filepath.Clean(sources[0]))
+	}
+
+	// A log that cannot be written must not fail the operation itself
+	if logErr := a.log.Record(entry); logErr != nil {
+		a.logger.Println("Failed to write audit log:", logErr)
+	}
+	return err
+}
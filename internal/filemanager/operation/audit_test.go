@@ -0,0 +1,96 @@
+package operation
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/directory"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+// recordedLog keeps entries in memory
+type recordedLog struct {
+	entries []types.AuditEntry
+}
+
+func (r *recordedLog) Record(entry types.AuditEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *recordedLog) Entries() ([]types.AuditEntry, error) {
+	return r.entries, nil
+}
+
+type AuditTestSuite struct {
+	suite.Suite
+	fs       types.FileSystem
+	log      *recordedLog
+	executor types.OperationExecutor
+}
+
+func (s *AuditTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	logger := log.New(io.Discard, "", 0)
+	s.log = &recordedLog{}
+	unqueued := func(types.Operation) (string, bool) { return "", false }
+	s.executor = &auditedExecutor{Executor: NewExecutor(s.fs, nil, logger).(*Executor), log: s.log, cwd: unqueued, logger: logger}
+
+	s.Require().NoError(s.fs.MkdirAll("/work/dir", 0755))
+	for _, path := range []string{"/work/a.txt", "/work/b.txt", "/work/dir/b.txt"} {
+		f, err := s.fs.Create(path, 0644)
+		s.Require().NoError(err)
+		s.Require().NoError(f.Close())
+	}
+}
+
+func (s *AuditTestSuite) TestRecordsResolvedTarget() {
+	suffix := func(types.Conflict) types.ConflictResolution { return types.ConflictSuffix }
+
+	tests := []struct {
+		op      types.Operation
+		resolve types.ConflictResolver
+		target  string
+		result  string
+	}{
+		{op: New(types.Move, "/work/a.txt", "/work/dir"), target: "/work/dir/a.txt", result: "ok"},
+		{op: New(types.Copy, "/work/b.txt", "/work/dir"), resolve: suffix, target: "/work/dir/b (1).txt", result: "ok"},
+		{op: New(types.Copy, "/work/b.txt", "/work/dir"), target: "/work/dir/b.txt", result: "failed"},
+		{op: New(types.Delete, "/work/b.txt", ""), target: "", result: "ok"},
+	}
+
+	for _, tt := range tests {
+		s.executor.Execute(context.Background(), tt.op, nil, tt.resolve)
+	}
+
+	s.Require().Len(s.log.entries, len(tests))
+	for i, tt := range tests {
+		entry := s.log.entries[i]
+		s.Equal(tt.target, entry.Target, Describe(tt.op))
+		s.Equal(tt.result, entry.Result, Describe(tt.op))
+		s.Equal("/work", entry.Cwd, Describe(tt.op))
+	}
+}
+
+// The directory recorded is the one shown when the operation was queued,
+// such as the one an entry cut elsewhere was pasted into
+func (s *AuditTestSuite) TestRecordsQueuedDirectory() {
+	logger := log.New(io.Discard, "", 0)
+	dirManager := directory.NewDirectoryManager("/work/dir", s.fs, logger)
+	manager := NewOperationManager(dirManager, s.fs, nil, types.SkipOnFailure, types.ConflictAsk, s.log, logger)
+
+	manager.QueueOperation(New(types.Move, "/work/a.txt", "/work/dir"))
+	s.Require().NoError(dirManager.ChangeDirectory("/work"))
+	manager.ExecuteOperations(context.Background(), nil, nil)
+
+	s.Require().Len(s.log.entries, 1)
+	s.Equal("/work/dir", s.log.entries[0].Cwd)
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
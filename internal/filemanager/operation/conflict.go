@@ -0,0 +1,52 @@
+package operation
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// destination is the path op creates, empty for operations that create
+// nothing
+func destination(op types.Operation) string {
+	switch op.Type() {
+	case types.Rename, types.Pack, types.Extract:
+		return filepath.Clean(op.Target())
+	case types.Move, types.Copy:
+		return filepath.Join(op.Target(), filepath.Base(op.Source()))
+	case types.Create:
+		return filepath.Clean(op.Source())
+	default:
+		return ""
+	}
+}
+
+// freeName returns path, or the first of "name (1).ext", "name (2).ext", …
+// that does not exist yet
+func freeName(fs types.FileSystem, path string) string {
+	dir, base := filepath.Split(path)
+	stem, ext := splitExt(base)
+
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+		if _, err := fs.Lstat(candidate); err != nil {
+			return candidate
+		}
+	}
+}
+
+// splitExt splits a name before its extension. Dot files have none and
+// ".tar.gz" style double extensions are kept together.
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return name, ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+	if inner := filepath.Ext(stem); inner == ".tar" {
+		return strings.TrimSuffix(stem, inner), inner + ext
+	}
+	return stem, ext
+}
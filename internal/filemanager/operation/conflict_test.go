@@ -0,0 +1,183 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"syscall"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConflictTestSuite struct {
+	suite.Suite
+	fs       types.FileSystem
+	executor types.OperationExecutor
+}
+
+func (s *ConflictTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.executor = NewExecutor(s.fs, nil, log.New(io.Discard, "", 0))
+
+	s.Require().NoError(s.fs.MkdirAll("/work/dir", 0755))
+	s.writeFile("/work/a.txt", "new")
+	s.writeFile("/work/dir/a.txt", "old")
+	s.writeFile("/work/dir/a (1).txt", "older")
+}
+
+func (s *ConflictTestSuite) writeFile(path, content string) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(content))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+func (s *ConflictTestSuite) readFile(path string) string {
+	f, err := s.fs.Open(path)
+	s.Require().NoError(err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	s.Require().NoError(err)
+	return string(content)
+}
+
+func (s *ConflictTestSuite) TestResolve() {
+	tests := []struct {
+		name       string
+		op         types.Operation
+		resolution types.ConflictResolution
+		wantErr    error
+		files      map[string]string
+	}{
+		{
+			name:       "overwrite",
+			op:         New(types.Move, "/work/a.txt", "/work/dir"),
+			resolution: types.ConflictOverwrite,
+			files:      map[string]string{"/work/dir/a.txt": "new"},
+		},
+		{
+			name:       "skip",
+			op:         New(types.Move, "/work/a.txt", "/work/dir"),
+			resolution: types.ConflictSkip,
+			wantErr:    types.ErrSkipped,
+			files:      map[string]string{"/work/a.txt": "new", "/work/dir/a.txt": "old"},
+		},
+		{
+			name:       "suffix picks the first free name",
+			op:         New(types.Copy, "/work/a.txt", "/work/dir"),
+			resolution: types.ConflictSuffix,
+			files:      map[string]string{"/work/dir/a.txt": "old", "/work/dir/a (1).txt": "older", "/work/dir/a (2).txt": "new"},
+		},
+		{
+			name:       "suffix on rename",
+			op:         New(types.Rename, "/work/a.txt", "/work/dir/a.txt"),
+			resolution: types.ConflictSuffix,
+			files:      map[string]string{"/work/dir/a (2).txt": "new"},
+		},
+		{
+			name:       "suffix on create",
+			op:         New(types.Create, "/work/dir/a.txt", ""),
+			resolution: types.ConflictSuffix,
+			files:      map[string]string{"/work/dir/a.txt": "old", "/work/dir/a (2).txt": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+
+			var asked []types.Conflict
+			err := s.executor.Execute(context.Background(), tt.op, nil, func(c types.Conflict) types.ConflictResolution {
+				asked = append(asked, c)
+				return tt.resolution
+			})
+
+			if tt.wantErr != nil {
+				s.ErrorIs(err, tt.wantErr)
+			} else {
+				s.NoError(err)
+			}
+			s.Len(asked, 1)
+			for path, content := range tt.files {
+				s.Equal(content, s.readFile(path), path)
+			}
+		})
+	}
+}
+
+// The target is only replaced once the operation succeeded
+func (s *ConflictTestSuite) TestOverwriteKeepsTargetOnFailure() {
+	overwrite := func(types.Conflict) types.ConflictResolution { return types.ConflictOverwrite }
+
+	s.Run("full device", func() {
+		s.SetupTest()
+		s.Require().NoError(s.fs.MkdirAll("/mnt/usb", 0755))
+		s.writeFile("/mnt/usb/a.txt", "old")
+		fsys := devices{FileSystem: s.fs, full: true}
+		executor := NewExecutor(fsys, nil, log.New(io.Discard, "", 0))
+
+		s.ErrorIs(executor.Execute(context.Background(), New(types.Move, "/work/a.txt", "/mnt/usb"), nil, overwrite), syscall.ENOSPC)
+		s.Equal("new", s.readFile("/work/a.txt"))
+		s.Equal("old", s.readFile("/mnt/usb/a.txt"))
+		s.entries("/mnt/usb", "a.txt")
+	})
+
+	s.Run("cancelled", func() {
+		s.SetupTest()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		s.ErrorIs(s.executor.Execute(ctx, New(types.Copy, "/work/a.txt", "/work/dir"), nil, overwrite), context.Canceled)
+		s.Equal("old", s.readFile("/work/dir/a.txt"))
+		s.entries("/work/dir", "a (1).txt", "a.txt")
+	})
+}
+
+func (s *ConflictTestSuite) entries(dir string, names ...string) {
+	entries, err := s.fs.ReadDir(dir)
+	s.Require().NoError(err)
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	s.ElementsMatch(names, got)
+}
+
+func (s *ConflictTestSuite) TestUnresolved() {
+	err := s.executor.Execute(context.Background(), New(types.Move, "/work/a.txt", "/work/dir"), nil, nil)
+
+	var conflict *types.ConflictError
+	s.Require().True(errors.As(err, &conflict))
+	s.Equal("/work/dir/a.txt", conflict.Target)
+}
+
+func (s *ConflictTestSuite) TestSplitExt() {
+	tests := []struct {
+		name string
+		stem string
+		ext  string
+	}{
+		{name: "a.txt", stem: "a", ext: ".txt"},
+		{name: "archive.tar.gz", stem: "archive", ext: ".tar.gz"},
+		{name: ".bashrc", stem: ".bashrc", ext: ""},
+		{name: "Makefile", stem: "Makefile", ext: ""},
+		{name: "v1.2.zip", stem: "v1.2", ext: ".zip"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			stem, ext := splitExt(tt.name)
+			s.Equal(tt.stem, stem)
+			s.Equal(tt.ext, ext)
+		})
+	}
+}
+
+func TestConflictSuite(t *testing.T) {
+	suite.Run(t, new(ConflictTestSuite))
+}
@@ -1,67 +1,307 @@
 package operation
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
 )
 
 type Executor struct {
-	dirManager types.DirectoryManager
+	fs        types.FileSystem
+	templates types.Templates // nil when created files start out empty
+	logger    types.Logger
 }
 
-func NewExecutor(dirManager types.DirectoryManager) types.OperationExecutor {
+func NewExecutor(fs types.FileSystem, templates types.Templates, logger types.Logger) types.OperationExecutor {
 	return &Executor{
-		dirManager: dirManager,
+		fs:        fs,
+		templates: templates,
+		logger:    logger,
 	}
 }
 
-func (e *Executor) Execute(op types.Operation) error {
-	if err := e.ValidateOperation(op); err != nil {
-		return fmt.Errorf("operation validation failed: %w", err)
+func (e *Executor) Execute(ctx context.Context, op types.Operation, progress types.ProgressFunc, resolve types.ConflictResolver) error {
+	_, err := e.execute(ctx, op, progress, resolve)
+	return err
+}
+
+// execute is Execute, also returning the path op created once conflicts
+// were resolved, empty when it creates none or got no further
+func (e *Executor) execute(ctx context.Context, op types.Operation, progress types.ProgressFunc, resolve types.ConflictResolver) (string, error) {
+	if err := e.validateSource(op); err != nil {
+		return "", fmt.Errorf("operation validation failed: %w", err)
+	}
+
+	dst, overwrite, err := e.resolveConflict(op, resolve)
+	if err != nil {
+		return "", err
+	}
+
+	// An existing target is only replaced once op succeeded
+	at := dst
+	if overwrite {
+		at = e.tempName(dst)
 	}
 
 	switch op.Type() {
 	case types.Delete:
-		return os.Remove(op.Source())
-	case types.Rename:
-		return os.Rename(op.Source(), op.Target())
-	case types.Move:
-		target := filepath.Join(op.Target(), filepath.Base(op.Source()))
-		return os.Rename(op.Source(), target)
+		err = e.fs.Remove(op.Source())
+	case types.Rename, types.Move:
+		err = e.rename(ctx, op.Source(), at, progress)
+	case types.Copy:
+		err = e.copy(ctx, op.Source(), at, progress)
+	case types.Pack:
+		err = e.pack(ctx, op.Sources(), at, progress)
+	case types.Extract:
+		err = vfs.ExtractArchive(ctx, e.fs, op.Source(), at, fileProgress(types.Progress{}, progress))
 	case types.Create:
-		if op.Source()[len(op.Source())-1] == '/' {
-			return os.MkdirAll(op.Source(), 0755)
+		if strings.HasSuffix(op.Source(), "/") {
+			err = e.fs.MkdirAll(at, 0755)
+		} else {
+			err = e.create(at, dst)
 		}
-		f, err := os.Create(op.Source())
+	default:
+		err = fmt.Errorf("unknown operation type: %v", op.Type())
+	}
+	if err == nil && overwrite {
+		err = e.replace(at, dst)
+	}
+	return dst, err
+}
+
+// resolveConflict returns the path op creates, which differs from its
+// destination when a free name was picked for it, and whether the entry
+// there is to be replaced
+func (e *Executor) resolveConflict(op types.Operation, resolve types.ConflictResolver) (string, bool, error) {
+	dst := destination(op)
+	if dst == "" || !e.exists(dst) || dst == filepath.Clean(op.Source()) && op.Type() != types.Create {
+		return dst, false, nil
+	}
+
+	conflict := types.Conflict{Operation: op, Target: dst}
+	if resolve == nil {
+		return "", false, &types.ConflictError{Conflict: conflict}
+	}
+
+	switch resolve(conflict) {
+	case types.ConflictOverwrite:
+		return dst, true, nil
+	case types.ConflictSuffix:
+		return freeName(e.fs, dst), false, nil
+	case types.ConflictSkip:
+		return "", false, types.ErrSkipped
+	default:
+		return "", false, &types.ConflictError{Conflict: conflict}
+	}
+}
+
+// tempName returns a free hidden name next to path. The name of path is
+// kept at its end, so archives are still told apart by their extension.
+func (e *Executor) tempName(path string) string {
+	candidate := filepath.Join(filepath.Dir(path), ".grease-"+filepath.Base(path))
+	if e.exists(candidate) {
+		return freeName(e.fs, candidate)
+	}
+	return candidate
+}
+
+// replace puts the entry at tmp in place of dst. dst is moved aside until
+// tmp took its place and put back when that fails.
+func (e *Executor) replace(tmp, dst string) error {
+	old := e.tempName(dst)
+	if err := e.fs.Rename(dst, old); err != nil {
+		return fmt.Errorf("failed to replace %s, the result is kept at %s: %w", dst, tmp, err)
+	}
+	if err := e.fs.Rename(tmp, dst); err != nil {
+		e.fs.Rename(old, dst)
+		return fmt.Errorf("failed to replace %s, the result is kept at %s: %w", dst, tmp, err)
+	}
+	if err := e.fs.RemoveAll(old); err != nil {
+		e.logger.Printf("Failed to remove the replaced %s at %s: %v", dst, old, err)
+	}
+	return nil
+}
+
+func (e *Executor) exists(path string) bool {
+	_, err := e.fs.Lstat(path)
+	return err == nil
+}
+
+// rename falls back to moveAcross when src and dst are on different devices
+func (e *Executor) rename(ctx context.Context, src, dst string, progress types.ProgressFunc) error {
+	err := e.fs.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	return e.moveAcross(ctx, src, dst, progress)
+}
+
+// moveAcross copies src to dst, checks the copy against the source and only
+// then removes the source. A failed, cancelled or differing copy is removed
+// instead.
+func (e *Executor) moveAcross(ctx context.Context, src, dst string, progress types.ProgressFunc) error {
+	if err := e.copy(ctx, src, dst, progress); err != nil {
+		return fmt.Errorf("copy across devices failed: %w", err)
+	}
+	if err := vfs.CompareTree(e.fs, e.fs, src, dst); err != nil {
+		e.fs.RemoveAll(dst)
+		return fmt.Errorf("copy across devices could not be verified: %w", err)
+	}
+
+	return e.fs.RemoveAll(src)
+}
+
+// copy copies src to dst reporting the bytes and files done so far. dst
+// must not exist, a partial copy is removed again.
+func (e *Executor) copy(ctx context.Context, src, dst string, progress types.ProgressFunc) error {
+	// Never clean up something that was there before
+	if _, err := e.fs.Lstat(dst); err == nil {
+		return fmt.Errorf("target already exists")
+	}
+
+	var p types.Progress
+	if progress != nil {
+		size, files, err := vfs.TreeSize(e.fs, src)
 		if err != nil {
 			return err
 		}
-		return f.Close()
-	default:
-		return fmt.Errorf("unknown operation type: %v", op.Type())
+		p.TotalBytes, p.TotalFiles = size, files
+		progress(p)
+	}
+
+	if err := vfs.CopyTree(ctx, e.fs, e.fs, src, dst, fileProgress(p, progress)); err != nil {
+		e.fs.RemoveAll(dst)
+		return err
+	}
+	return nil
+}
+
+// create makes an empty file at dst, or one seeded from the template that
+// matches name. A template that cannot be read leaves the file empty.
+func (e *Executor) create(dst, name string) error {
+	var content []byte
+	if e.templates != nil {
+		var err error
+		if content, _, err = e.templates.Content(name); err != nil {
+			e.logger.Printf("Failed to read template for %s: %v", name, err)
+			content = nil
+		}
+	}
+
+	f, err := e.fs.Create(dst, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		e.fs.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// pack writes sources into the archive dst reporting the bytes and files
+// done so far
+func (e *Executor) pack(ctx context.Context, sources []string, dst string, progress types.ProgressFunc) error {
+	var p types.Progress
+	if progress != nil {
+		for _, source := range sources {
+			size, files, err := vfs.TreeSize(e.fs, source)
+			if err != nil {
+				return err
+			}
+			p.TotalBytes += size
+			p.TotalFiles += files
+		}
+		progress(p)
+	}
+	return vfs.WriteArchive(ctx, e.fs, dst, sources, fileProgress(p, progress))
+}
+
+// fileProgress adds the bytes and files reported by vfs up to p, which
+// holds the totals when they are known, and passes it on to progress
+func fileProgress(p types.Progress, progress types.ProgressFunc) vfs.CopyProgress {
+	if progress == nil {
+		return nil
+	}
+
+	return func(path string, written int64, done bool) {
+		p.Path = path
+		p.Bytes += written
+		if done {
+			p.Files++
+		}
+		progress(p)
 	}
 }
 
 func (e *Executor) ValidateOperation(op types.Operation) error {
+	if err := e.validateSource(op); err != nil {
+		return err
+	}
+	if _, _, err := e.resolveConflict(op, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateSource checks what op needs to exist before it can run
+func (e *Executor) validateSource(op types.Operation) error {
 	switch op.Type() {
-	case types.Delete:
-		if _, err := os.Stat(op.Source()); err != nil {
+	case types.Delete, types.Rename:
+		if _, err := e.fs.Lstat(op.Source()); err != nil {
 			return fmt.Errorf("source does not exist: %w", err)
 		}
-	case types.Rename, types.Move:
-		if _, err := os.Stat(op.Source()); err != nil {
+	case types.Move, types.Copy:
+		if _, err := e.fs.Lstat(op.Source()); err != nil {
 			return fmt.Errorf("source does not exist: %w", err)
 		}
-		if _, err := os.Stat(op.Target()); err == nil {
-			return fmt.Errorf("target already exists")
+		// The target of a move or copy is the directory the source goes into
+		if info, err := e.fs.Stat(op.Target()); err != nil || !info.IsDir() {
+			return fmt.Errorf("target directory does not exist")
 		}
-	case types.Create:
-		if _, err := os.Stat(op.Source()); err == nil {
-			return fmt.Errorf("file/directory already exists")
+	case types.Pack:
+		for _, source := range op.Sources() {
+			if _, err := e.fs.Lstat(source); err != nil {
+				return fmt.Errorf("source does not exist: %w", err)
+			}
+			// The archive would end up packing itself
+			if within(op.Target(), source) {
+				return fmt.Errorf("archive cannot be created inside %s", source)
+			}
+		}
+		return e.validateParent(op.Target())
+	case types.Extract:
+		if _, err := e.fs.Lstat(op.Source()); err != nil {
+			return fmt.Errorf("source does not exist: %w", err)
 		}
+		if !vfs.IsArchive(op.Source()) {
+			return fmt.Errorf("%s is not an archive", filepath.Base(op.Source()))
+		}
+		return e.validateParent(op.Target())
+	}
+	return nil
+}
+
+// validateParent checks that the directory path is created in exists
+func (e *Executor) validateParent(path string) error {
+	if info, err := e.fs.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return fmt.Errorf("target directory does not exist")
 	}
 	return nil
 }
+
+// within reports whether path is dir or lies below it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
@@ -0,0 +1,334 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExecutorTestSuite struct {
+	suite.Suite
+	fs       types.FileSystem
+	executor types.OperationExecutor
+}
+
+func (s *ExecutorTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.executor = NewExecutor(s.fs, nil, log.New(io.Discard, "", 0))
+
+	s.Require().NoError(s.fs.MkdirAll("/work/dir", 0755))
+	s.writeFile("/work/a.txt", "hello")
+}
+
+func (s *ExecutorTestSuite) writeFile(path, content string) {
+	f, err := s.fs.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write([]byte(content))
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+func (s *ExecutorTestSuite) exists(path string) bool {
+	_, err := s.fs.Lstat(path)
+	return err == nil
+}
+
+func (s *ExecutorTestSuite) TestExecute() {
+	tests := []struct {
+		name    string
+		op      types.Operation
+		exist   []string
+		missing []string
+		wantErr bool
+	}{
+		{
+			name:    "delete file",
+			op:      New(types.Delete, "/work/a.txt", ""),
+			missing: []string{"/work/a.txt"},
+		},
+		{
+			name:    "delete missing file",
+			op:      New(types.Delete, "/work/missing.txt", ""),
+			wantErr: true,
+		},
+		{
+			name:    "rename file",
+			op:      New(types.Rename, "/work/a.txt", "/work/b.txt"),
+			exist:   []string{"/work/b.txt"},
+			missing: []string{"/work/a.txt"},
+		},
+		{
+			name:    "rename onto existing entry",
+			op:      New(types.Rename, "/work/a.txt", "/work/dir"),
+			exist:   []string{"/work/a.txt", "/work/dir"},
+			wantErr: true,
+		},
+		{
+			name:    "move into directory",
+			op:      New(types.Move, "/work/a.txt", "/work/dir"),
+			exist:   []string{"/work/dir/a.txt"},
+			missing: []string{"/work/a.txt"},
+		},
+		{
+			name:  "copy into directory",
+			op:    New(types.Copy, "/work/a.txt", "/work/dir"),
+			exist: []string{"/work/a.txt", "/work/dir/a.txt"},
+		},
+		{
+			name:  "create file",
+			op:    New(types.Create, "/work/new.txt", ""),
+			exist: []string{"/work/new.txt"},
+		},
+		{
+			name:  "create nested directory",
+			op:    New(types.Create, "/work/x/y/", ""),
+			exist: []string{"/work/x", "/work/x/y"},
+		},
+		{
+			name:    "create existing file",
+			op:      New(types.Create, "/work/a.txt", ""),
+			wantErr: true,
+		},
+		{
+			name:  "pack into archive",
+			op:    NewPack([]string{"/work/a.txt", "/work/dir"}, "/work/out.zip"),
+			exist: []string{"/work/a.txt", "/work/out.zip"},
+		},
+		{
+			name:    "pack with unknown extension",
+			op:      NewPack([]string{"/work/a.txt"}, "/work/out.rar"),
+			missing: []string{"/work/out.rar"},
+			wantErr: true,
+		},
+		{
+			name:    "pack into a source",
+			op:      NewPack([]string{"/work/dir"}, "/work/dir/out.zip"),
+			missing: []string{"/work/dir/out.zip"},
+			wantErr: true,
+		},
+		{
+			name:    "extract a file that is no archive",
+			op:      New(types.Extract, "/work/a.txt", "/work/a"),
+			missing: []string{"/work/a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+
+			err := s.executor.Execute(context.Background(), tt.op, nil, nil)
+
+			if tt.wantErr {
+				s.Error(err)
+			} else {
+				s.NoError(err)
+			}
+			for _, path := range tt.exist {
+				s.True(s.exists(path), "%s should exist", path)
+			}
+			for _, path := range tt.missing {
+				s.False(s.exists(path), "%s should not exist", path)
+			}
+		})
+	}
+}
+
+func (s *ExecutorTestSuite) TestPackAndExtract() {
+	s.Require().NoError(s.fs.MkdirAll("/work/dir/sub", 0755))
+	s.writeFile("/work/dir/sub/b.txt", "nested")
+	s.Require().NoError(s.fs.Symlink("../a.txt", "/work/dir/link"))
+
+	for _, archive := range []string{"/work/out.zip", "/work/out.tar", "/work/out.tar.gz"} {
+		s.Run(archive, func() {
+			var last types.Progress
+			progress := func(p types.Progress) { last = p }
+
+			pack := NewPack([]string{"/work/a.txt", "/work/dir"}, archive)
+			s.Require().NoError(s.executor.Execute(context.Background(), pack, progress, nil))
+			s.Equal(int64(len("hello")+len("nested")), last.Bytes)
+			s.Equal(2, last.Files)
+
+			extract := New(types.Extract, archive, "/work/extracted")
+			s.Require().NoError(s.executor.Execute(context.Background(), extract, nil, nil))
+			s.NoError(vfs.CompareTree(s.fs, s.fs, "/work/a.txt", "/work/extracted/a.txt"))
+			s.NoError(vfs.CompareTree(s.fs, s.fs, "/work/dir", "/work/extracted/dir"))
+
+			s.Require().NoError(s.fs.RemoveAll("/work/extracted"))
+		})
+	}
+}
+
+func (s *ExecutorTestSuite) TestMoveKeepsContent() {
+	s.Require().NoError(s.executor.Execute(context.Background(), New(types.Move, "/work/a.txt", "/work/dir"), nil, nil))
+
+	f, err := s.fs.Open("/work/dir/a.txt")
+	s.Require().NoError(err)
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	s.Require().NoError(err)
+	s.Equal("hello", string(content))
+}
+
+// devices is a memory file system where /mnt is a separate device, which
+// is full when set
+type devices struct {
+	types.FileSystem
+	full bool
+}
+
+func (d devices) Create(path string, perm os.FileMode) (io.WriteCloser, error) {
+	if d.full && strings.HasPrefix(path, "/mnt/") {
+		return nil, &os.PathError{Op: "open", Path: path, Err: syscall.ENOSPC}
+	}
+	return d.FileSystem.Create(path, perm)
+}
+
+func (d devices) Rename(oldpath, newpath string) error {
+	if strings.HasPrefix(oldpath, "/mnt/") != strings.HasPrefix(newpath, "/mnt/") {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	return d.FileSystem.Rename(oldpath, newpath)
+}
+
+func (s *ExecutorTestSuite) TestMoveAcrossDevices() {
+	fsys := devices{FileSystem: s.fs}
+	executor := NewExecutor(fsys, nil, log.New(io.Discard, "", 0))
+
+	var progress []types.Progress
+	report := func(p types.Progress) { progress = append(progress, p) }
+
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.Require().NoError(fsys.MkdirAll("/mnt/usb", 0755))
+	s.writeFile("/work/dir/b.txt", "world!")
+	s.Require().NoError(fsys.Chtimes("/work/dir/b.txt", modTime, modTime))
+	s.Require().NoError(fsys.Symlink("b.txt", "/work/dir/link"))
+
+	s.Require().NoError(executor.Execute(context.Background(), New(types.Move, "/work/dir", "/mnt/usb"), report, nil))
+
+	s.False(s.exists("/work/dir"))
+	info, err := fsys.Stat("/mnt/usb/dir/b.txt")
+	s.Require().NoError(err)
+	s.Equal(os.FileMode(0644), info.Mode().Perm())
+	s.True(modTime.Equal(info.ModTime()))
+
+	target, err := fsys.Readlink("/mnt/usb/dir/link")
+	s.Require().NoError(err)
+	s.Equal("b.txt", target)
+
+	s.Require().NotEmpty(progress)
+	last := progress[len(progress)-1]
+	s.Equal(int64(6), last.Bytes)
+	s.Equal(int64(6), last.TotalBytes)
+	s.Equal(1, last.Files)
+	s.Equal(1, last.TotalFiles)
+	s.Equal("/work/dir/b.txt", last.Path)
+}
+
+func (s *ExecutorTestSuite) TestMoveAcrossDevicesKeepsSourceOnFailure() {
+	fsys := devices{FileSystem: s.fs, full: true}
+	executor := NewExecutor(fsys, nil, log.New(io.Discard, "", 0))
+
+	// The directory is created before the file fails, the partial copy is
+	// removed again
+	s.Require().NoError(fsys.MkdirAll("/mnt/usb", 0755))
+	s.writeFile("/work/dir/b.txt", "world!")
+
+	s.ErrorIs(executor.Execute(context.Background(), New(types.Move, "/work/dir", "/mnt/usb"), nil, nil), syscall.ENOSPC)
+	s.True(s.exists("/work/dir/b.txt"))
+	s.False(s.exists("/mnt/usb/dir"))
+}
+
+// fixedTemplates seeds every file with content, or fails with err
+type fixedTemplates struct {
+	content string
+	err     error
+}
+
+func (t fixedTemplates) Content(path string) ([]byte, bool, error) {
+	if t.err != nil {
+		return nil, false, t.err
+	}
+	return []byte(t.content), true, nil
+}
+
+func (s *ExecutorTestSuite) TestCreateFromTemplate() {
+	tests := []struct {
+		name      string
+		templates types.Templates
+		expected  string
+	}{
+		{name: "template content", templates: fixedTemplates{content: "package main\n"}, expected: "package main\n"},
+		{name: "unreadable template", templates: fixedTemplates{err: errors.New("is a directory")}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+			executor := NewExecutor(s.fs, tt.templates, log.New(io.Discard, "", 0))
+
+			s.Require().NoError(executor.Execute(context.Background(), New(types.Create, "/work/main.go", ""), nil, nil))
+
+			f, err := s.fs.Open("/work/main.go")
+			s.Require().NoError(err)
+			defer f.Close()
+			content, err := io.ReadAll(f)
+			s.Require().NoError(err)
+			s.Equal(tt.expected, string(content))
+		})
+	}
+}
+
+// failingWrites is a memory file system whose files cannot be written to
+type failingWrites struct {
+	types.FileSystem
+}
+
+type failingWriter struct {
+	io.WriteCloser
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, syscall.ENOSPC
+}
+
+func (f failingWrites) Create(path string, perm os.FileMode) (io.WriteCloser, error) {
+	w, err := f.FileSystem.Create(path, perm)
+	if err != nil {
+		return nil, err
+	}
+	return failingWriter{w}, nil
+}
+
+func (s *ExecutorTestSuite) TestCreateRemovesFileOnFailure() {
+	fsys := failingWrites{FileSystem: s.fs}
+	executor := NewExecutor(fsys, fixedTemplates{content: "hello"}, log.New(io.Discard, "", 0))
+
+	s.ErrorIs(executor.Execute(context.Background(), New(types.Create, "/work/new.txt", ""), nil, nil), syscall.ENOSPC)
+	s.False(s.exists("/work/new.txt"))
+}
+
+func (s *ExecutorTestSuite) TestCancelledCopy() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.executor.Execute(ctx, New(types.Copy, "/work/a.txt", "/work/dir"), nil, nil)
+	s.ErrorIs(err, context.Canceled)
+	s.False(s.exists("/work/dir/a.txt"))
+}
+
+func TestExecutorSuite(t *testing.T) {
+	suite.Run(t, new(ExecutorTestSuite))
+}
@@ -0,0 +1,134 @@
+package operation
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Job executes the queued operations of a manager in the background. Its
+// state is polled, so the UI decides how often to redraw.
+type Job struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	answers  chan types.ConflictResolution
+	mu       sync.Mutex
+	progress types.Progress
+	conflict *types.Conflict
+	always   types.ConflictResolution // answer for all further conflicts
+	results  []types.OperationResult
+}
+
+// Start begins executing what is queued on m. Conflicts are resolved as
+// configured on m, or wait for Resolve when that is types.ConflictAsk.
+// Operations queued on m while the job runs are left for the next one.
+func Start(m types.OperationManager) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &Job{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		// Buffered so that an answer racing with Cancel never blocks
+		answers: make(chan types.ConflictResolution, 1),
+		always:  m.ConflictResolution(),
+	}
+
+	go func() {
+		defer close(j.done)
+		defer cancel()
+
+		results := m.ExecuteOperations(ctx, j.report, func(c types.Conflict) types.ConflictResolution {
+			return j.ask(ctx, c)
+		})
+
+		j.mu.Lock()
+		j.results = results
+		j.mu.Unlock()
+	}()
+
+	return j
+}
+
+func (j *Job) report(p types.Progress) {
+	j.mu.Lock()
+	j.progress = p
+	j.mu.Unlock()
+}
+
+// ask blocks until the conflict is resolved or the job is cancelled, which
+// skips it
+func (j *Job) ask(ctx context.Context, c types.Conflict) types.ConflictResolution {
+	j.mu.Lock()
+	if j.always != types.ConflictAsk {
+		defer j.mu.Unlock()
+		return j.always
+	}
+	j.conflict = &c
+	j.mu.Unlock()
+
+	select {
+	case answer := <-j.answers:
+		return answer
+	case <-ctx.Done():
+		j.mu.Lock()
+		j.conflict = nil
+		j.mu.Unlock()
+		return types.ConflictSkip
+	}
+}
+
+// Conflict returns the conflict waiting for Resolve, if any
+func (j *Job) Conflict() (types.Conflict, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.conflict == nil {
+		return types.Conflict{}, false
+	}
+	return *j.conflict, true
+}
+
+// Resolve answers the waiting conflict. With all set the answer is used for
+// every further conflict of the job as well.
+func (j *Job) Resolve(resolution types.ConflictResolution, all bool) {
+	j.mu.Lock()
+	if j.conflict == nil {
+		j.mu.Unlock()
+		return
+	}
+	j.conflict = nil
+	if all {
+		j.always = resolution
+	}
+	j.mu.Unlock()
+
+	j.answers <- resolution
+}
+
+// Progress returns the latest progress reported
+func (j *Job) Progress() types.Progress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress
+}
+
+// Cancel stops the running operation and skips the rest
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+func (j *Job) Done() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the job is done and returns its results
+func (j *Job) Wait() []types.OperationResult {
+	<-j.done
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.results
+}
@@ -1,6 +1,9 @@
 package operation
 
 import (
+	"context"
+	"sync"
+
 	"github.com/gunererd/grease/internal/filemanager/types"
 )
 
@@ -8,36 +11,127 @@ type Manager struct {
 	queue      *OperationQueue
 	executor   types.OperationExecutor
 	dirManager types.DirectoryManager
+	policy     types.FailurePolicy
+	onConflict types.ConflictResolution
+	templates  types.Templates
+	audit      types.AuditLog
 	logger     types.Logger
+
+	mu       sync.Mutex
+	queuedIn map[types.Operation]string // directory shown when each operation was queued
 }
 
-func NewOperationManager(dirManager types.DirectoryManager, logger types.Logger) types.OperationManager {
-	executor := NewExecutor(dirManager)
-	return &Manager{
-		queue:      NewOperationQueue(executor),
-		executor:   executor,
+func NewOperationManager(
+	dirManager types.DirectoryManager,
+	fs types.FileSystem,
+	templates types.Templates,
+	policy types.FailurePolicy,
+	onConflict types.ConflictResolution,
+	audit types.AuditLog,
+	logger types.Logger,
+) types.OperationManager {
+	base := NewExecutor(fs, templates, logger).(*Executor)
+	m := &Manager{
 		dirManager: dirManager,
+		policy:     policy,
+		onConflict: onConflict,
+		templates:  templates,
+		audit:      audit,
 		logger:     logger,
+		queuedIn:   make(map[types.Operation]string),
+	}
+
+	m.executor = base
+	if audit != nil {
+		m.executor = &auditedExecutor{Executor: base, log: audit, cwd: m.cwd, logger: logger}
 	}
+	m.queue = NewOperationQueue(m.executor)
+	return m
 }
 
 func (m *Manager) QueueOperation(op types.Operation) {
+	m.mu.Lock()
+	m.queuedIn[op] = m.dirManager.CurrentPath()
+	m.mu.Unlock()
 	m.queue.Push(op)
 }
 
-func (m *Manager) ExecuteOperations() error {
-	if err := m.queue.Execute(); err != nil {
-		return err
+// cwd returns the directory that was shown when op was queued
+func (m *Manager) cwd(op types.Operation) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir, ok := m.queuedIn[op]
+	return dir, ok
+}
+
+// forget drops the directories of operations that left the queue
+func (m *Manager) forget() {
+	queued := make(map[types.Operation]bool)
+	for _, op := range m.queue.Operations() {
+		queued[op] = true
 	}
 
-	// After executing operations, reload the directory
-	return nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for op := range m.queuedIn {
+		if !queued[op] {
+			delete(m.queuedIn, op)
+		}
+	}
+}
+
+func (m *Manager) ExecuteOperations(
+	ctx context.Context,
+	progress types.ProgressFunc,
+	resolve types.ConflictResolver,
+) []types.OperationResult {
+	results := m.queue.Execute(ctx, m.policy, progress, resolve)
+	m.forget()
+	for _, result := range results {
+		if result.Status == types.OperationFailed {
+			m.logger.Printf("Operation failed: %s: %v", Describe(result.Operation), result.Err)
+		}
+	}
+	return results
+}
+
+func (m *Manager) SkipOperation() (types.OperationResult, bool) {
+	op, ok := m.queue.Skip()
+	if !ok {
+		return types.OperationResult{}, false
+	}
+	return types.OperationResult{Operation: op, Status: types.OperationSkipped}, true
+}
+
+func (m *Manager) AbortOperations() []types.OperationResult {
+	return skipAll(m.queue.Abort(), nil)
+}
+
+func (m *Manager) FailurePolicy() types.FailurePolicy {
+	return m.policy
+}
+
+func (m *Manager) ConflictResolution() types.ConflictResolution {
+	return m.onConflict
+}
+
+func (m *Manager) Templates() types.Templates {
+	return m.templates
+}
+
+func (m *Manager) AuditLog() types.AuditLog {
+	return m.audit
 }
 
 func (m *Manager) GetPendingOperations() []types.Operation {
 	return m.queue.Operations()
 }
 
+func (m *Manager) RemoveOperation(op types.Operation) bool {
+	return m.queue.Remove(op)
+}
+
 func (m *Manager) Clear() {
 	m.queue.Clear()
+	m.forget()
 }
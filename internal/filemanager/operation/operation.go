@@ -1,18 +1,33 @@
 package operation
 
-import "github.com/gunererd/grease/internal/filemanager/types"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
 
 type operation struct {
-	opType types.OperationType
-	source string
-	target string
+	opType  types.OperationType
+	sources []string
+	target  string
 }
 
 func New(opType types.OperationType, source string, target string) types.Operation {
 	return &operation{
-		opType: opType,
-		source: source,
-		target: target,
+		opType:  opType,
+		sources: []string{source},
+		target:  target,
+	}
+}
+
+// NewPack returns an operation writing sources into the archive at target
+func NewPack(sources []string, target string) types.Operation {
+	return &operation{
+		opType:  types.Pack,
+		sources: append([]string{}, sources...),
+		target:  target,
 	}
 }
 
@@ -21,9 +36,42 @@ func (o *operation) Type() types.OperationType {
 }
 
 func (o *operation) Source() string {
-	return o.source
+	if len(o.sources) == 0 {
+		return ""
+	}
+	return o.sources[0]
+}
+
+func (o *operation) Sources() []string {
+	return o.sources
 }
 
 func (o *operation) Target() string {
 	return o.target
 }
+
+// Describe renders op for the user, e.g. "move /a/b.txt -> /c/"
+func Describe(op types.Operation) string {
+	switch op.Type() {
+	case types.Delete:
+		return fmt.Sprintf("delete %s", op.Source())
+	case types.Rename:
+		return fmt.Sprintf("rename %s -> %s", op.Source(), filepath.Base(op.Target()))
+	case types.Move:
+		return fmt.Sprintf("move %s -> %s/", op.Source(), op.Target())
+	case types.Copy:
+		return fmt.Sprintf("copy %s -> %s/", op.Source(), op.Target())
+	case types.Create:
+		return fmt.Sprintf("create %s", op.Source())
+	case types.Pack:
+		names := make([]string, len(op.Sources()))
+		for i, source := range op.Sources() {
+			names[i] = filepath.Base(source)
+		}
+		return fmt.Sprintf("pack %s -> %s", strings.Join(names, ", "), op.Target())
+	case types.Extract:
+		return fmt.Sprintf("extract %s -> %s/", op.Source(), op.Target())
+	default:
+		return fmt.Sprintf("%v %s %s", op.Type(), op.Source(), op.Target())
+	}
+}
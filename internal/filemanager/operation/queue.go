@@ -1,11 +1,19 @@
 package operation
 
 import (
+	"context"
+	"errors"
+	"sync"
+
 	"github.com/gunererd/grease/internal/filemanager/types"
 )
 
+// OperationQueue is safe for concurrent use, operations may be queued while
+// a batch executes in the background
 type OperationQueue struct {
+	mu         sync.Mutex
 	operations []types.Operation
+	stopped    []types.Operation // the failed operation and the rest of its batch
 	executor   types.OperationExecutor
 }
 
@@ -16,28 +24,174 @@ func NewOperationQueue(executor types.OperationExecutor) *OperationQueue {
 	}
 }
 
+// Operations returns a copy of what is queued
 func (q *OperationQueue) Operations() []types.Operation {
-	return q.operations
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]types.Operation{}, q.operations...)
 }
 
 func (q *OperationQueue) Push(op types.Operation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	q.operations = append(q.operations, op)
 }
 
 func (q *OperationQueue) Clear() {
-	q.operations = q.operations[:0]
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.operations = nil
 }
 
 func (q *OperationQueue) IsEmpty() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	return len(q.operations) == 0
 }
 
-func (q *OperationQueue) Execute() error {
+// Execute runs the operations queued when it is called. Operations queued
+// meanwhile stay queued for the next call. With the ask policy a failure
+// stops the batch and leaves the failed and remaining operations at the
+// front of the queue.
+func (q *OperationQueue) Execute(
+	ctx context.Context,
+	policy types.FailurePolicy,
+	progress types.ProgressFunc,
+	resolve types.ConflictResolver,
+) []types.OperationResult {
+	q.mu.Lock()
+	batch := append([]types.Operation{}, q.operations...)
+	q.stopped = nil
+	q.mu.Unlock()
+
+	results := make([]types.OperationResult, 0, len(batch))
+	for i, op := range batch {
+		if err := ctx.Err(); err != nil {
+			results = append(results, skipAll(batch[i:], err)...)
+			q.done(batch, nil)
+			return results
+		}
+
+		err := q.executor.Execute(ctx, op, stepProgress(op, i+1, len(batch), progress), resolve)
+		if err == nil {
+			results = append(results, types.OperationResult{Operation: op, Status: types.OperationSucceeded})
+			continue
+		}
+		if errors.Is(err, types.ErrSkipped) {
+			results = append(results, types.OperationResult{Operation: op, Status: types.OperationSkipped})
+			continue
+		}
+		results = append(results, types.OperationResult{Operation: op, Status: types.OperationFailed, Err: err})
+
+		switch {
+		case policy == types.StopOnFailure, ctx.Err() != nil:
+			results = append(results, skipAll(batch[i+1:], ctx.Err())...)
+			q.done(batch, nil)
+			return results
+		case policy == types.AskOnFailure:
+			q.done(batch, batch[i:])
+			q.mu.Lock()
+			q.stopped = batch[i:]
+			q.mu.Unlock()
+			return results
+		}
+	}
+	q.done(batch, nil)
+	return results
+}
+
+// done removes the operations of batch that are still queued and puts
+// remaining back in front of those queued since
+func (q *OperationQueue) done(batch, remaining []types.Operation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	taken := make(map[types.Operation]bool, len(batch))
+	for _, op := range batch {
+		taken[op] = true
+	}
+	kept := append([]types.Operation{}, remaining...)
+	for _, op := range q.operations {
+		if !taken[op] {
+			kept = append(kept, op)
+		}
+	}
+	q.operations = kept
+}
+
+// stepProgress fills in the position of op in the batch before passing
+// progress on
+func stepProgress(op types.Operation, step, steps int, progress types.ProgressFunc) types.ProgressFunc {
+	if progress == nil {
+		return nil
+	}
+
+	progress(types.Progress{Operation: op, Step: step, Steps: steps})
+	return func(p types.Progress) {
+		p.Operation, p.Step, p.Steps = op, step, steps
+		progress(p)
+	}
+}
+
+// skipAll reports ops as skipped, with err as the reason when it is set
+func skipAll(ops []types.Operation, err error) []types.OperationResult {
+	results := make([]types.OperationResult, 0, len(ops))
+	for _, op := range ops {
+		results = append(results, types.OperationResult{Operation: op, Status: types.OperationSkipped, Err: err})
+	}
+	return results
+}
+
+func (q *OperationQueue) Skip() (types.Operation, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.operations) == 0 {
+		return nil, false
+	}
+	op := q.operations[0]
+	q.operations = append([]types.Operation{}, q.operations[1:]...)
+	return op, true
+}
+
+func (q *OperationQueue) Remove(op types.Operation) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, queued := range q.operations {
+		if queued == op {
+			q.operations = append(q.operations[:i:i], q.operations[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Abort drops what is left of a batch stopped by a failure under the ask
+// policy and returns the operations that followed the failed one. Those
+// queued while the batch ran stay queued.
+func (q *OperationQueue) Abort() []types.Operation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stopped := make(map[types.Operation]bool, len(q.stopped))
+	for _, op := range q.stopped {
+		stopped[op] = true
+	}
+	var dropped, kept []types.Operation
 	for _, op := range q.operations {
-		if err := q.executor.Execute(op); err != nil {
-			return err
+		if stopped[op] {
+			dropped = append(dropped, op)
+		} else {
+			kept = append(kept, op)
+		}
+	}
+	q.operations = kept
+
+	var rest []types.Operation
+	for _, op := range dropped {
+		if len(q.stopped) > 0 && op != q.stopped[0] {
+			rest = append(rest, op)
 		}
 	}
-	q.Clear()
-	return nil
+	q.stopped = nil
+	return rest
 }
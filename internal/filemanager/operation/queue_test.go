@@ -0,0 +1,155 @@
+package operation
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueTestSuite struct {
+	suite.Suite
+	queue *OperationQueue
+}
+
+func (s *QueueTestSuite) SetupTest() {
+	fs := vfs.NewMemory()
+	s.queue = NewOperationQueue(NewExecutor(fs, nil, log.New(io.Discard, "", 0)))
+
+	s.Require().NoError(fs.MkdirAll("/work", 0755))
+	s.queue.Push(New(types.Create, "/work/a", ""))
+	s.queue.Push(New(types.Delete, "/work/missing", ""))
+	s.queue.Push(New(types.Create, "/work/b", ""))
+}
+
+func (s *QueueTestSuite) TestExecute() {
+	tests := []struct {
+		name      string
+		policy    types.FailurePolicy
+		statuses  []types.OperationStatus
+		remaining int
+	}{
+		{
+			name:     "stop skips the rest",
+			policy:   types.StopOnFailure,
+			statuses: []types.OperationStatus{types.OperationSucceeded, types.OperationFailed, types.OperationSkipped},
+		},
+		{
+			name:     "skip carries on",
+			policy:   types.SkipOnFailure,
+			statuses: []types.OperationStatus{types.OperationSucceeded, types.OperationFailed, types.OperationSucceeded},
+		},
+		{
+			name:      "ask keeps the failed operation queued",
+			policy:    types.AskOnFailure,
+			statuses:  []types.OperationStatus{types.OperationSucceeded, types.OperationFailed},
+			remaining: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.SetupTest()
+
+			results := s.queue.Execute(context.Background(), tt.policy, nil, nil)
+
+			var statuses []types.OperationStatus
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+				if result.Status == types.OperationFailed {
+					s.Error(result.Err)
+				} else {
+					s.NoError(result.Err)
+				}
+			}
+			s.Equal(tt.statuses, statuses)
+			s.Len(s.queue.Operations(), tt.remaining)
+		})
+	}
+}
+
+func (s *QueueTestSuite) TestSkipAfterAsk() {
+	s.queue.Execute(context.Background(), types.AskOnFailure, nil, nil)
+
+	op, ok := s.queue.Skip()
+	s.Require().True(ok)
+	s.Equal("/work/missing", op.Source())
+
+	results := s.queue.Execute(context.Background(), types.AskOnFailure, nil, nil)
+	s.Require().Len(results, 1)
+	s.Equal(types.OperationSucceeded, results[0].Status)
+	s.True(s.queue.IsEmpty())
+}
+
+func (s *QueueTestSuite) TestCancelled() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := s.queue.Execute(ctx, types.SkipOnFailure, nil, nil)
+	s.Require().Len(results, 3)
+	for _, result := range results {
+		s.Equal(types.OperationSkipped, result.Status)
+		s.ErrorIs(result.Err, context.Canceled)
+	}
+	s.True(s.queue.IsEmpty())
+}
+
+func (s *QueueTestSuite) TestQueuedWhileExecuting() {
+	queued := New(types.Create, "/work/c", "")
+	progress := func(p types.Progress) {
+		if p.Step == 1 && p.Operation.Source() == "/work/a" {
+			s.queue.Push(queued)
+		}
+	}
+
+	results := s.queue.Execute(context.Background(), types.AskOnFailure, progress, nil)
+	s.Require().Len(results, 2)
+	s.Equal([]string{"/work/missing", "/work/b", "/work/c"}, sources(s.queue.Operations()))
+
+	s.queue.Skip()
+	results = s.queue.Execute(context.Background(), types.AskOnFailure, nil, nil)
+	s.Len(results, 2)
+	s.True(s.queue.IsEmpty())
+}
+
+// Aborting drops only the failed batch, operations queued meanwhile stay
+func (s *QueueTestSuite) TestAbortAfterAsk() {
+	queued := New(types.Create, "/work/c", "")
+	progress := func(p types.Progress) {
+		if p.Step == 1 && p.Operation.Source() == "/work/a" {
+			s.queue.Push(queued)
+		}
+	}
+	s.queue.Execute(context.Background(), types.AskOnFailure, progress, nil)
+
+	s.Equal([]string{"/work/b"}, sources(s.queue.Abort()))
+	s.Equal([]string{"/work/c"}, sources(s.queue.Operations()))
+
+	// Nothing is left of the batch to abort
+	s.Empty(s.queue.Abort())
+	s.Equal([]string{"/work/c"}, sources(s.queue.Operations()))
+}
+
+func (s *QueueTestSuite) TestAbortEmptyQueue() {
+	s.queue.Execute(context.Background(), types.AskOnFailure, nil, nil)
+	s.queue.Clear()
+
+	s.Empty(s.queue.Abort())
+	s.True(s.queue.IsEmpty())
+}
+
+func sources(ops []types.Operation) []string {
+	var paths []string
+	for _, op := range ops {
+		paths = append(paths, op.Source())
+	}
+	return paths
+}
+
+func TestQueueSuite(t *testing.T) {
+	suite.Run(t, new(QueueTestSuite))
+}
@@ -0,0 +1,88 @@
+package picker
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Picker keeps the marked entries by absolute path, so marks survive
+// navigating between directories
+type Picker struct {
+	root      string
+	only      types.PickKind
+	marks     []string
+	confirmed bool
+}
+
+// New returns a picker limited to entries of kind only. When root is not
+// empty navigation stays within it.
+func New(root string, only types.PickKind) types.Picker {
+	if root != "" {
+		root = filepath.Clean(root)
+	}
+	return &Picker{
+		root: root,
+		only: only,
+	}
+}
+
+func (p *Picker) Within(dir string) bool {
+	if p.root == "" {
+		return true
+	}
+	rel, err := filepath.Rel(p.root, filepath.Clean(dir))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (p *Picker) Toggle(path string, isDir bool) error {
+	switch {
+	case p.only == types.PickFiles && isDir:
+		return errors.New("only files can be picked")
+	case p.only == types.PickDirs && !isDir:
+		return errors.New("only directories can be picked")
+	}
+
+	path = filepath.Clean(path)
+	for i, mark := range p.marks {
+		if mark == path {
+			p.marks = append(p.marks[:i], p.marks[i+1:]...)
+			return nil
+		}
+	}
+	p.marks = append(p.marks, path)
+	return nil
+}
+
+func (p *Picker) Marked(path string) bool {
+	path = filepath.Clean(path)
+	for _, mark := range p.marks {
+		if mark == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Picker) Marks() []string {
+	return append([]string(nil), p.marks...)
+}
+
+func (p *Picker) Confirm() {
+	p.confirmed = true
+}
+
+func (p *Picker) Picked() ([]string, bool) {
+	if !p.confirmed {
+		return nil, false
+	}
+	return p.Marks(), true
+}
+
+// Entry returns the path of a listing entry of dir and whether it is a
+// directory, which the listing shows by a trailing "/"
+func Entry(dir, name string) (string, bool) {
+	return filepath.Join(dir, strings.TrimSuffix(name, "/")), strings.HasSuffix(name, "/")
+}
@@ -0,0 +1,76 @@
+package picker
+
+import (
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type PickerTestSuite struct {
+	suite.Suite
+}
+
+func (s *PickerTestSuite) TestWithin() {
+	tests := []struct {
+		name     string
+		root     string
+		dir      string
+		expected bool
+	}{
+		{name: "no root", root: "", dir: "/", expected: true},
+		{name: "the root itself", root: "/home/me", dir: "/home/me", expected: true},
+		{name: "below the root", root: "/home/me", dir: "/home/me/src/grease", expected: true},
+		{name: "trailing slash", root: "/home/me/", dir: "/home/me/src", expected: true},
+		{name: "parent of the root", root: "/home/me", dir: "/home", expected: false},
+		{name: "sibling sharing a prefix", root: "/home/me", dir: "/home/meg", expected: false},
+		{name: "unclean path escaping the root", root: "/home/me", dir: "/home/me/src/../..", expected: false},
+		{name: "name starting with dots", root: "/home/me", dir: "/home/me/..cache", expected: true},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.expected, New(tt.root, types.PickAny).Within(tt.dir))
+		})
+	}
+}
+
+func (s *PickerTestSuite) TestToggle() {
+	p := New("", types.PickAny)
+	s.Require().NoError(p.Toggle("/tmp/b", false))
+	s.Require().NoError(p.Toggle("/tmp/a/", true))
+	s.Require().NoError(p.Toggle("/tmp/c", false))
+	s.True(p.Marked("/tmp/a"))
+
+	s.Require().NoError(p.Toggle("/tmp/b", false))
+	s.False(p.Marked("/tmp/b"))
+	s.Equal([]string{"/tmp/a", "/tmp/c"}, p.Marks())
+}
+
+func (s *PickerTestSuite) TestKind() {
+	files := New("", types.PickFiles)
+	s.NoError(files.Toggle("/tmp/a", false))
+	s.Error(files.Toggle("/tmp/dir", true))
+
+	dirs := New("", types.PickDirs)
+	s.NoError(dirs.Toggle("/tmp/dir", true))
+	s.Error(dirs.Toggle("/tmp/a", false))
+	s.Equal([]string{"/tmp/dir"}, dirs.Marks())
+}
+
+func (s *PickerTestSuite) TestPicked() {
+	p := New("", types.PickAny)
+	s.Require().NoError(p.Toggle("/tmp/a", false))
+
+	_, ok := p.Picked()
+	s.False(ok)
+
+	p.Confirm()
+	picked, ok := p.Picked()
+	s.True(ok)
+	s.Equal([]string{"/tmp/a"}, picked)
+}
+
+func TestPickerSuite(t *testing.T) {
+	suite.Run(t, new(PickerTestSuite))
+}
@@ -0,0 +1,58 @@
+package filemanager
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const progressBarWidth = 20
+
+// renderProgress describes a running batch in one line of at most width
+// cells, e.g. "[2/3] ████░░░░ 40% 12.0 MiB/30.0 MiB 4/10 files a.iso"
+func renderProgress(p types.Progress, width int) string {
+	if p.Operation == nil {
+		return "Starting operations… ctrl+c cancels"
+	}
+
+	step := fmt.Sprintf("[%d/%d]", p.Step, p.Steps)
+	if p.TotalBytes == 0 && p.TotalFiles == 0 {
+		return fmt.Sprintf("%s %s  ctrl+c cancels", step, operation.Describe(p.Operation))
+	}
+
+	fraction := 1.0
+	if p.TotalBytes > 0 {
+		fraction = float64(p.Bytes) / float64(p.TotalBytes)
+	}
+	filled := int(fraction * progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+
+	line := fmt.Sprintf("%s %s %3d%% %s/%s %d/%d files",
+		step, bar, int(fraction*100), formatBytes(p.Bytes), formatBytes(p.TotalBytes), p.Files, p.TotalFiles)
+	if p.Path != "" {
+		line += " " + filepath.Base(p.Path)
+	}
+
+	hint := "  ctrl+c cancels"
+	if len([]rune(line))+len(hint) <= width {
+		line += hint
+	}
+	return line
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,197 @@
+package reconcile
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Plan compares an edited listing of dir with its original one and returns
+// the operations that turn the directory into the listing, in an order that
+// can be executed one by one. Directories end with "/" in both listings.
+//
+// Names found in both listings are kept wherever they moved to. Of the
+// rest, removed and added names of the same kind that follow the same kept
+// name are paired up in order as renames, the way changing a line renames
+// it while editing. What is left over is deleted or created. Empty lines
+// are ignored.
+func Plan(dir string, original, edited []string) ([]types.Operation, error) {
+	original = nonEmpty(original)
+	edited = nonEmpty(edited)
+
+	if err := validate(edited); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(original))
+	for _, name := range original {
+		existing[name] = true
+	}
+	kept := make(map[string]bool, len(edited))
+	for _, name := range edited {
+		if existing[name] {
+			kept[name] = true
+		}
+	}
+
+	removed := groupByAnchor(original, func(name string) bool { return !kept[name] }, kept)
+	added := groupByAnchor(edited, func(name string) bool { return !existing[name] }, kept)
+
+	var deletes, creates []types.Operation
+	renames := make(map[string]string)
+	for anchor, names := range removed {
+		files, dirs := byKind(added[anchor])
+		for _, name := range names {
+			// Only entries of the same kind pair up, a file is never
+			// renamed into a directory
+			candidates := &files
+			if strings.HasSuffix(name, "/") {
+				candidates = &dirs
+			}
+			if len(*candidates) == 0 {
+				deletes = append(deletes, operation.New(types.Delete, filepath.Join(dir, name), ""))
+				continue
+			}
+			renames[name] = (*candidates)[0]
+			*candidates = (*candidates)[1:]
+		}
+		added[anchor] = append(files, dirs...)
+	}
+	for _, news := range added {
+		for _, name := range news {
+			// A trailing slash makes the executor create a directory
+			path := filepath.Join(dir, name)
+			if strings.HasSuffix(name, "/") {
+				path += "/"
+			}
+			creates = append(creates, operation.New(types.Create, path, ""))
+		}
+	}
+
+	ordered, err := orderRenames(dir, renames)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBySource(deletes)
+	sortBySource(creates)
+
+	// Deleting first frees names that renames and creates may take
+	ops := append(deletes, ordered...)
+	return append(ops, creates...), nil
+}
+
+// byKind splits names into files and directories, keeping their order
+func byKind(names []string) (files, dirs []string) {
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			dirs = append(dirs, name)
+		} else {
+			files = append(files, name)
+		}
+	}
+	return files, dirs
+}
+
+func nonEmpty(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// validate rejects names that cannot be entries of a single directory and
+// names listed twice
+func validate(listing []string) error {
+	seen := make(map[string]bool, len(listing))
+	for _, name := range listing {
+		base := strings.TrimSuffix(name, "/")
+		if base == "" || base == "." || base == ".." || strings.Contains(base, "/") {
+			return fmt.Errorf("%q is not a valid entry name", name)
+		}
+		if seen[base] {
+			return fmt.Errorf("%s is listed more than once", base)
+		}
+		seen[base] = true
+	}
+	return nil
+}
+
+// groupByAnchor collects the names of listing that match, keyed by the
+// closest kept name before them ("" at the top)
+func groupByAnchor(listing []string, match func(string) bool, kept map[string]bool) map[string][]string {
+	groups := make(map[string][]string)
+	anchor := ""
+	for _, name := range listing {
+		switch {
+		case kept[name]:
+			anchor = name
+		case match(name):
+			groups[anchor] = append(groups[anchor], name)
+		}
+	}
+	return groups
+}
+
+// orderRenames puts each rename after the one that frees its target, which
+// can only be an entry of the other kind with the same name. Renames that
+// form a cycle, such as a file and a directory trading names, are refused.
+func orderRenames(dir string, renames map[string]string) ([]types.Operation, error) {
+	sources := make([]string, 0, len(renames))
+	for from := range renames {
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+
+	// A file and a directory of the same name cannot both exist, so targets
+	// are matched against sources without their slash
+	byBase := make(map[string]string, len(renames))
+	for from := range renames {
+		byBase[strings.TrimSuffix(from, "/")] = from
+	}
+
+	var ordered []types.Operation
+	done := make(map[string]bool, len(renames))
+	var visit func(from string, path []string) error
+	visit = func(from string, path []string) error {
+		if done[from] {
+			return nil
+		}
+		for _, p := range path {
+			if p == from {
+				return fmt.Errorf("renames form a cycle: %s -> %s", strings.Join(path, " -> "), from)
+			}
+		}
+
+		to := renames[from]
+		if next, ok := byBase[strings.TrimSuffix(to, "/")]; ok {
+			if err := visit(next, append(path, from)); err != nil {
+				return err
+			}
+		}
+
+		done[from] = true
+		ordered = append(ordered, operation.New(types.Rename, filepath.Join(dir, from), filepath.Join(dir, to)))
+		return nil
+	}
+
+	for _, from := range sources {
+		if err := visit(from, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+func sortBySource(ops []types.Operation) {
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Source() < ops[j].Source()
+	})
+}
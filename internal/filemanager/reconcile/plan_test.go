@@ -0,0 +1,130 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/stretchr/testify/suite"
+)
+
+type PlanTestSuite struct {
+	suite.Suite
+}
+
+func (s *PlanTestSuite) TestPlan() {
+	original := []string{"docs/", "src/", "a.txt", "b.txt", "c.txt"}
+
+	tests := []struct {
+		name     string
+		original []string
+		edited   []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "unchanged",
+			original: original,
+			edited:   original,
+			expected: nil,
+		},
+		{
+			name:     "reordered lines are kept",
+			original: original,
+			edited:   []string{"c.txt", "a.txt", "src/", "b.txt", "docs/"},
+			expected: nil,
+		},
+		{
+			name:     "removed line deletes",
+			original: original,
+			edited:   []string{"docs/", "src/", "a.txt", "c.txt"},
+			expected: []string{"delete /d/b.txt"},
+		},
+		{
+			name:     "changed line renames",
+			original: original,
+			edited:   []string{"docs/", "source/", "a.txt", "b.md", "c.txt"},
+			expected: []string{"rename /d/b.txt -> b.md", "rename /d/src -> source"},
+		},
+		{
+			name:     "added lines create",
+			original: original,
+			edited:   []string{"docs/", "src/", "lib/", "a.txt", "b.txt", "c.txt", "new.txt", ""},
+			expected: []string{"create /d/lib/", "create /d/new.txt"},
+		},
+		{
+			name:     "more removed than added in one place",
+			original: original,
+			edited:   []string{"docs/", "src/", "z.txt"},
+			expected: []string{"delete /d/b.txt", "delete /d/c.txt", "rename /d/a.txt -> z.txt"},
+		},
+		{
+			name:     "reordered with an addition",
+			original: []string{"a", "b"},
+			edited:   []string{"b", "a", "x"},
+			expected: []string{"create /d/x"},
+		},
+		{
+			name:     "rename waits for a directory of the same name",
+			original: []string{"y/", "x"},
+			edited:   []string{"z/", "y"},
+			expected: []string{"rename /d/y -> z", "rename /d/x -> y"},
+		},
+		{
+			name:     "delete frees a name for the other kind",
+			original: []string{"b/", "keep"},
+			edited:   []string{"keep", "b"},
+			expected: []string{"delete /d/b", "create /d/b"},
+		},
+		{
+			name:     "cycle is refused",
+			original: []string{"y/", "x"},
+			edited:   []string{"x/", "y"},
+			err:      "cycle",
+		},
+		{
+			name:     "files and directories do not pair up",
+			original: []string{"a", "keep"},
+			edited:   []string{"a/", "keep"},
+			expected: []string{"delete /d/a", "create /d/a/"},
+		},
+		{
+			name:     "pairs follow kind and order",
+			original: []string{"d1/", "f1", "f2"},
+			edited:   []string{"g1", "e1/"},
+			expected: []string{"delete /d/f2", "rename /d/d1 -> e1", "rename /d/f1 -> g1"},
+		},
+		{
+			name:     "duplicate names",
+			original: []string{"a"},
+			edited:   []string{"a", "b", "b/"},
+			err:      "listed more than once",
+		},
+		{
+			name:     "names with a slash",
+			original: []string{"a"},
+			edited:   []string{"a", "x/y"},
+			err:      "not a valid entry name",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			ops, err := Plan("/d", tt.original, tt.edited)
+			if tt.err != "" {
+				s.ErrorContains(err, tt.err)
+				return
+			}
+			s.Require().NoError(err)
+
+			var described []string
+			for _, op := range ops {
+				described = append(described, operation.Describe(op))
+			}
+			s.Equal(tt.expected, described)
+		})
+	}
+}
+
+func TestPlanSuite(t *testing.T) {
+	suite.Run(t, new(PlanTestSuite))
+}
@@ -0,0 +1,114 @@
+package rename
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rename is a single entry rename within one directory
+type Rename struct {
+	Line int
+	From string
+	To   string
+}
+
+// Collision describes why a set of renames cannot be applied
+type Collision struct {
+	Target  string
+	Sources []string
+	Reason  string
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("%s -> %s: %s", strings.Join(c.Sources, ", "), c.Target, c.Reason)
+}
+
+// Plan applies sub to the given lines of a listing and returns the renames
+// in an order that can be executed one by one. Nothing is returned but the
+// collisions when two entries would end up with the same name, a name
+// becomes invalid or renames form a cycle.
+func Plan(listing []string, lines []int, sub *Substitution) ([]Rename, []Collision) {
+	final := make([]string, len(listing))
+	copy(final, listing)
+
+	var renames []Rename
+	var collisions []Collision
+	for _, line := range lines {
+		from := listing[line]
+		if from == "" {
+			continue
+		}
+
+		to := sub.Apply(from)
+		if to == from {
+			continue
+		}
+
+		name := strings.TrimSuffix(to, "/")
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			collisions = append(collisions, Collision{Target: to, Sources: []string{from}, Reason: "invalid name"})
+			continue
+		}
+
+		final[line] = to
+		renames = append(renames, Rename{Line: line, From: from, To: to})
+	}
+
+	sources := make(map[string][]string)
+	for line, name := range final {
+		if name == "" {
+			continue
+		}
+		key := strings.TrimSuffix(name, "/")
+		sources[key] = append(sources[key], listing[line])
+	}
+	for _, r := range renames {
+		key := strings.TrimSuffix(r.To, "/")
+		if len(sources[key]) > 1 {
+			collisions = append(collisions, Collision{Target: r.To, Sources: sources[key], Reason: "multiple entries map to the same name"})
+			sources[key] = nil
+		}
+	}
+
+	if len(collisions) > 0 {
+		sort.Slice(collisions, func(i, j int) bool { return collisions[i].Target < collisions[j].Target })
+		return nil, collisions
+	}
+
+	return order(renames)
+}
+
+// order sorts renames so that no rename targets a name that is still taken
+// by an entry waiting to be renamed itself
+func order(renames []Rename) ([]Rename, []Collision) {
+	pending := make(map[string]bool, len(renames))
+	for _, r := range renames {
+		pending[strings.TrimSuffix(r.From, "/")] = true
+	}
+
+	ordered := make([]Rename, 0, len(renames))
+	remaining := renames
+	for len(remaining) > 0 {
+		var blocked []Rename
+		for _, r := range remaining {
+			if pending[strings.TrimSuffix(r.To, "/")] {
+				blocked = append(blocked, r)
+				continue
+			}
+			ordered = append(ordered, r)
+			delete(pending, strings.TrimSuffix(r.From, "/"))
+		}
+
+		if len(blocked) == len(remaining) {
+			var collisions []Collision
+			for _, r := range blocked {
+				collisions = append(collisions, Collision{Target: r.To, Sources: []string{r.From}, Reason: "target is renamed in a cycle"})
+			}
+			return nil, collisions
+		}
+		remaining = blocked
+	}
+
+	return ordered, nil
+}
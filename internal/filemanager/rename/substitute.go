@@ -0,0 +1,126 @@
+package rename
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrMissingPattern   = errors.New("missing pattern")
+	ErrUnterminated     = errors.New("unterminated substitution")
+	ErrInvalidDelimiter = errors.New("delimiter must not be a letter, digit, backslash or space")
+
+	backrefPattern = regexp.MustCompile(`\\([0-9])`)
+)
+
+// Substitution is a parsed "/pattern/replacement/flags" argument of :s
+type Substitution struct {
+	re          *regexp.Regexp
+	replacement string
+	global      bool
+}
+
+// Parse reads a vim style substitution. Any punctuation character can be
+// used as the delimiter and escaped with a backslash inside the pattern or
+// replacement. Capture groups are referenced as $1, ${name} or \1. Flags are
+// g (replace every match instead of the first) and i (ignore case).
+func Parse(args string) (*Substitution, error) {
+	if args == "" {
+		return nil, ErrMissingPattern
+	}
+
+	delim := rune(args[0])
+	if delim == '\\' || unicode.IsLetter(delim) || unicode.IsDigit(delim) || unicode.IsSpace(delim) {
+		return nil, ErrInvalidDelimiter
+	}
+
+	parts, rest, err := split(args[1:], delim, 2)
+	if err != nil {
+		return nil, err
+	}
+	if parts[0] == "" {
+		return nil, ErrMissingPattern
+	}
+
+	sub := &Substitution{
+		replacement: backrefPattern.ReplaceAllString(parts[1], "$${$1}"),
+	}
+
+	pattern := parts[0]
+	for _, flag := range strings.TrimSpace(rest) {
+		switch flag {
+		case 'g':
+			sub.global = true
+		case 'i':
+			pattern = "(?i)" + pattern
+		default:
+			return nil, fmt.Errorf("unknown flag %q", flag)
+		}
+	}
+
+	sub.re, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Apply returns name with the substitution applied. Directory names keep
+// their trailing slash out of reach of the pattern.
+func (s *Substitution) Apply(name string) string {
+	suffix := ""
+	if strings.HasSuffix(name, "/") {
+		name, suffix = strings.TrimSuffix(name, "/"), "/"
+	}
+
+	if s.global {
+		return s.re.ReplaceAllString(name, s.replacement) + suffix
+	}
+
+	loc := s.re.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return name + suffix
+	}
+
+	var result []byte
+	result = append(result, name[:loc[0]]...)
+	result = s.re.ExpandString(result, s.replacement, name, loc)
+	result = append(result, name[loc[1]:]...)
+	return string(result) + suffix
+}
+
+// split reads count delimited fields, unescaping escaped delimiters. The
+// last field may be left unterminated. The remainder after the last
+// delimiter is returned separately.
+func split(s string, delim rune, count int) ([]string, string, error) {
+	fields := make([]string, 0, count)
+	var current strings.Builder
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' && i+1 < len(runes) && runes[i+1] == delim {
+			current.WriteRune(delim)
+			i++
+			continue
+		}
+		if r == delim {
+			fields = append(fields, current.String())
+			current.Reset()
+			if len(fields) == count {
+				return fields, string(runes[i+1:]), nil
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+
+	if len(fields) == count-1 {
+		return append(fields, current.String()), "", nil
+	}
+	return nil, "", ErrUnterminated
+}
@@ -0,0 +1,188 @@
+package rename
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SubstituteTestSuite struct {
+	suite.Suite
+}
+
+func (s *SubstituteTestSuite) TestApply() {
+	tests := []struct {
+		name     string
+		args     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "first match only",
+			args:     "/a/b/",
+			input:    "banana.txt",
+			expected: "bbnana.txt",
+		},
+		{
+			name:     "global flag",
+			args:     "/a/b/g",
+			input:    "banana.txt",
+			expected: "bbnbnb.txt",
+		},
+		{
+			name:     "dollar capture groups",
+			args:     `/(\w+)-(\d+)/$2-$1/`,
+			input:    "report-2024.pdf",
+			expected: "2024-report.pdf",
+		},
+		{
+			name:     "backslash capture groups",
+			args:     `/(\w+)-(\d+)/\2_\1/`,
+			input:    "report-2024.pdf",
+			expected: "2024_report.pdf",
+		},
+		{
+			name:     "ignore case flag",
+			args:     "/JPEG$/jpg/i",
+			input:    "photo.jpeg",
+			expected: "photo.jpg",
+		},
+		{
+			name:     "alternative delimiter with escaped slash free pattern",
+			args:     "#txt#md#",
+			input:    "notes.txt",
+			expected: "notes.md",
+		},
+		{
+			name:     "escaped delimiter",
+			args:     `/a\/b/c/`,
+			input:    "a/b",
+			expected: "c",
+		},
+		{
+			name:     "unterminated replacement",
+			args:     "/old/new",
+			input:    "old.txt",
+			expected: "new.txt",
+		},
+		{
+			name:     "directory suffix is preserved",
+			args:     "/$/_old/",
+			input:    "build/",
+			expected: "build_old/",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			sub, err := Parse(tt.args)
+			s.Require().NoError(err)
+			s.Equal(tt.expected, sub.Apply(tt.input))
+		})
+	}
+}
+
+func (s *SubstituteTestSuite) TestParseErrors() {
+	tests := []struct {
+		name string
+		args string
+	}{
+		{name: "empty", args: ""},
+		{name: "letter delimiter", args: "xaxbx"},
+		{name: "missing replacement", args: "/a"},
+		{name: "empty pattern", args: "//b/"},
+		{name: "bad regex", args: "/(/b/"},
+		{name: "unknown flag", args: "/a/b/z"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := Parse(tt.args)
+			s.Error(err)
+		})
+	}
+}
+
+func (s *SubstituteTestSuite) TestPlan() {
+	tests := []struct {
+		name       string
+		listing    []string
+		args       string
+		renames    []Rename
+		collisions int
+	}{
+		{
+			name:    "independent renames",
+			listing: []string{"a.txt", "b.txt", "c.md"},
+			args:    "/txt$/md/",
+			renames: []Rename{
+				{Line: 0, From: "a.txt", To: "a.md"},
+				{Line: 1, From: "b.txt", To: "b.md"},
+			},
+		},
+		{
+			name:       "two sources map to one target",
+			listing:    []string{"a1.txt", "a2.txt"},
+			args:       `/\d//`,
+			collisions: 1,
+		},
+		{
+			name:       "target taken by an untouched entry",
+			listing:    []string{"a.txt", "a.md"},
+			args:       "/txt/md/",
+			collisions: 1,
+		},
+		{
+			name:    "capture group reference",
+			listing: []string{"1", "2"},
+			args:    `/(\d)/${1}0/`,
+			renames: []Rename{
+				{Line: 0, From: "1", To: "10"},
+				{Line: 1, From: "2", To: "20"},
+			},
+		},
+		{
+			name:    "chain is ordered so targets are freed first",
+			listing: []string{"a", "aa"},
+			args:    "/^a/aa/",
+			renames: []Rename{
+				{Line: 1, From: "aa", To: "aaa"},
+				{Line: 0, From: "a", To: "aa"},
+			},
+		},
+		{
+			name:       "swap is a cycle",
+			listing:    []string{"ab", "ba"},
+			args:       "/^(a|b)(a|b)$/${2}${1}/",
+			collisions: 2,
+		},
+		{
+			name:       "names with slashes are rejected",
+			listing:    []string{"a_b"},
+			args:       "/_/\\//",
+			collisions: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			sub, err := Parse(tt.args)
+			s.Require().NoError(err)
+
+			lines := make([]int, len(tt.listing))
+			for i := range lines {
+				lines[i] = i
+			}
+
+			renames, collisions := Plan(tt.listing, lines, sub)
+			s.Len(collisions, tt.collisions)
+			if tt.collisions == 0 {
+				s.Equal(tt.renames, renames)
+			}
+		})
+	}
+}
+
+func TestSubstituteSuite(t *testing.T) {
+	suite.Run(t, new(SubstituteTestSuite))
+}
@@ -0,0 +1,177 @@
+package filemanager
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/compare"
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const jobTickInterval = 100 * time.Millisecond
+
+// jobTickMsg polls a running job
+type jobTickMsg struct {
+	job *operation.Job
+}
+
+// applyOperations starts executing the queued operations of a save. The
+// outcome is reported in the status line once they are done.
+func (fm *Filemanager) applyOperations() {
+	if fm.job != nil {
+		fm.editor.SetMessage("Operations are still running, ctrl+c cancels them", true)
+		return
+	}
+
+	fm.results = nil
+	fm.executeOperations()
+}
+
+// RunOperations executes ops in the background the way a save does. They
+// are refused while the operations or edits of a save are still pending.
+func (fm *Filemanager) RunOperations(ops []types.Operation) {
+	if fm.job != nil || fm.unsaved() {
+		fm.editor.SetMessage("Operations are still pending, finish them first", true)
+		return
+	}
+
+	for _, op := range ops {
+		fm.opManager.QueueOperation(op)
+	}
+	fm.applyOperations()
+}
+
+// executeOperations runs what is queued in the background
+func (fm *Filemanager) executeOperations() {
+	if len(fm.opManager.GetPendingOperations()) == 0 {
+		fm.finishOperations()
+		return
+	}
+
+	fm.job = operation.Start(fm.opManager)
+	fm.queueCmd(fm.tickJob())
+}
+
+func (fm *Filemanager) tickJob() tea.Cmd {
+	job := fm.job
+	return tea.Tick(jobTickInterval, func(time.Time) tea.Msg {
+		return jobTickMsg{job: job}
+	})
+}
+
+// updateJob shows the progress of the running job until it is done. With
+// the ask policy a failure leaves the rest queued and waits for
+// answerFailure.
+func (fm *Filemanager) updateJob(msg jobTickMsg) tea.Cmd {
+	if msg.job != fm.job {
+		return nil
+	}
+
+	if !fm.job.Done() {
+		if c, ok := fm.job.Conflict(); ok {
+			fm.editor.SetMessage(fm.conflictPrompt(c), true)
+		} else {
+			fm.editor.SetMessage(renderProgress(fm.job.Progress(), fm.editor.Width()), false)
+		}
+		return fm.tickJob()
+	}
+
+	results := fm.job.Wait()
+	fm.job = nil
+	fm.results = append(fm.results, results...)
+
+	if fm.opManager.FailurePolicy() == types.AskOnFailure && len(results) > 0 &&
+		len(fm.opManager.GetPendingOperations()) > 0 &&
+		results[len(results)-1].Status == types.OperationFailed {
+		failed := results[len(results)-1]
+		fm.asking = true
+		fm.editor.SetMessage(fmt.Sprintf("[r]etry, [s]kip or [a]bort? %s failed: %v",
+			operation.Describe(failed.Operation), failed.Err), true)
+		return nil
+	}
+
+	fm.finishOperations()
+	return nil
+}
+
+// cancelJob stops the running job, the operations not done yet are skipped
+func (fm *Filemanager) cancelJob() {
+	fm.job.Cancel()
+	fm.editor.SetMessage("Cancelling…", true)
+}
+
+// answerFailure handles the key pressed at the prompt for a failed
+// operation
+func (fm *Filemanager) answerFailure(msg tea.KeyMsg) {
+	switch msg.String() {
+	case "r":
+		fm.asking = false
+		fm.executeOperations()
+	case "s":
+		fm.asking = false
+		// The failed operation was already reported, drop it silently
+		fm.opManager.SkipOperation()
+		fm.executeOperations()
+	case "a", "esc", "ctrl+c":
+		fm.asking = false
+		fm.results = append(fm.results, fm.opManager.AbortOperations()...)
+		fm.finishOperations()
+	}
+}
+
+func (fm *Filemanager) finishOperations() {
+	compared, comparing := fm.scratch.(*compare.Scratch)
+	switch {
+	case comparing:
+		// Reading both trees again takes a while, the listing is refreshed
+		// once that is done
+		fm.startCompare(compared.Dirs())
+	case fm.scratch != nil:
+		fm.refreshScratch()
+	case fm.unsaved():
+		// Edits made while the operations ran are left for the next save,
+		// reloading would hide them
+		fm.logger.Println("Not reloading after operations: unsaved changes")
+	default:
+		if err := fm.reload(); err != nil {
+			fm.logger.Println("Failed to reload directory:", err)
+		}
+	}
+	if message, isError := summarize(fm.results); message != "" {
+		fm.editor.SetMessage(message, isError)
+	}
+}
+
+// Results returns the outcome of the operations of the last save
+func (fm *Filemanager) Results() []types.OperationResult {
+	return fm.results
+}
+
+// summarize describes results in one line, naming the first failure
+func summarize(results []types.OperationResult) (string, bool) {
+	var failed, skipped int
+	var first *types.OperationResult
+	for i, result := range results {
+		switch result.Status {
+		case types.OperationFailed:
+			failed++
+			if first == nil {
+				first = &results[i]
+			}
+		case types.OperationSkipped:
+			skipped++
+		}
+	}
+
+	switch {
+	case len(results) == 0:
+		return "", false
+	case first == nil:
+		return fmt.Sprintf("%d operation(s) applied", len(results)), false
+	default:
+		return fmt.Sprintf("%d of %d operation(s) failed, %d skipped: %s: %v (:results for details)",
+			failed, len(results), skipped, operation.Describe(first.Operation), first.Err), true
+	}
+}
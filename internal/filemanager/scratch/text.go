@@ -0,0 +1,39 @@
+package scratch
+
+import (
+	"errors"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+var ErrReadOnly = errors.New("scratch is read-only")
+
+// Text is a read-only scratch showing fixed lines, such as a preview or the
+// output of a command
+type Text struct {
+	name  string
+	lines []string
+}
+
+func NewText(name string, lines []string) types.Scratch {
+	return &Text{
+		name:  name,
+		lines: lines,
+	}
+}
+
+func (t *Text) Name() string {
+	return t.name
+}
+
+func (t *Text) Lines() ([]string, error) {
+	return t.lines, nil
+}
+
+func (t *Text) Open(line string) error {
+	return nil
+}
+
+func (t *Text) Write(lines []string) error {
+	return ErrReadOnly
+}
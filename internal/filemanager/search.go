@@ -0,0 +1,108 @@
+package filemanager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/grep"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// maxSearchMatches is how many matches are collected before a search stops
+const maxSearchMatches = 10000
+
+// searchFinishedMsg carries the matches of a search started by Grep
+type searchFinishedMsg struct {
+	id        int
+	root      string
+	pattern   string
+	matches   []types.SearchMatch
+	truncated bool
+}
+
+// Grep searches the files below the current directory for lines matching
+// pattern in the background and lists them once it is done. The pattern is
+// a regular expression, matched ignoring case unless it has upper case
+// letters.
+func (fm *Filemanager) Grep(pattern string) {
+	if pattern == "" {
+		fm.editor.SetMessage("grep: a pattern to search for is needed", true)
+		return
+	}
+
+	expr := pattern
+	if !strings.ContainsFunc(pattern, unicode.IsUpper) {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		fm.editor.SetMessage(fmt.Sprintf("grep: %v", err), true)
+		return
+	}
+
+	fm.cancelSearch()
+	ctx, cancel := context.WithCancel(context.Background())
+	fm.cancelGrep = cancel
+	fm.searchID++
+
+	id, root := fm.searchID, fm.dirManager.CurrentPath()
+	results := fm.searcher.Search(ctx, root, re)
+	fm.editor.SetMessage(fmt.Sprintf("Searching for %s…", pattern), false)
+	fm.queueCmd(func() tea.Msg {
+		msg := searchFinishedMsg{id: id, root: root, pattern: pattern}
+		for match := range results {
+			if len(msg.matches) == maxSearchMatches {
+				msg.truncated = true
+				cancel()
+				break
+			}
+			msg.matches = append(msg.matches, match)
+		}
+		return msg
+	})
+}
+
+func (fm *Filemanager) finishSearch(msg searchFinishedMsg) {
+	// A search cancelled by leaving the directory has nothing to show
+	if msg.id != fm.searchID || fm.cancelGrep == nil {
+		return
+	}
+	fm.cancelSearch()
+
+	if len(msg.matches) == 0 {
+		fm.editor.SetMessage(fmt.Sprintf("No matches for %s", msg.pattern), false)
+		return
+	}
+
+	// The files are searched in parallel, so the matches come in any order
+	sort.Slice(msg.matches, func(i, j int) bool {
+		a, b := msg.matches[i], msg.matches[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+
+	if err := fm.OpenScratch(grep.NewScratch(msg.root, msg.matches, fm.edit)); err != nil {
+		fm.logger.Println("Failed to show search results:", err)
+		return
+	}
+	message := fmt.Sprintf("%d matches for %s", len(msg.matches), msg.pattern)
+	if msg.truncated {
+		message += fmt.Sprintf(", stopped after the first %d", maxSearchMatches)
+	}
+	fm.editor.SetMessage(message, false)
+}
+
+// cancelSearch stops a search started by Grep, its matches are dropped
+func (fm *Filemanager) cancelSearch() {
+	if fm.cancelGrep != nil {
+		fm.cancelGrep()
+		fm.cancelGrep = nil
+	}
+}
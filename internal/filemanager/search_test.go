@@ -0,0 +1,68 @@
+package filemanager
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gunererd/grease/internal/editor"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchTestSuite struct {
+	suite.Suite
+	fm *Filemanager
+}
+
+func (s *SearchTestSuite) SetupTest() {
+	fs := vfs.NewMemory()
+	s.Require().NoError(fs.MkdirAll("/work", 0755))
+	s.Require().NoError(fs.MkdirAll("/other", 0755))
+
+	state := s.T().TempDir()
+	e, err := editor.Initialize()
+	s.Require().NoError(err)
+	fm, err := Initialize(e,
+		WithFileSystem(fs),
+		WithLog(filepath.Join(state, "log")),
+		WithBookmarkFile(filepath.Join(state, "bookmarks")),
+		WithFrecencyFile(filepath.Join(state, "frecency")),
+	)
+	s.Require().NoError(err)
+	s.fm = fm.(*Filemanager)
+	s.Require().NoError(s.fm.LoadDirectory("/work"))
+}
+
+func (s *SearchTestSuite) results(id int) searchFinishedMsg {
+	return searchFinishedMsg{
+		id:      id,
+		root:    "/work",
+		pattern: "main",
+		matches: []types.SearchMatch{{Path: "main.go", Line: 1, Text: "package main"}},
+	}
+}
+
+func (s *SearchTestSuite) TestShowsResults() {
+	s.fm.Grep("main")
+	s.fm.finishSearch(s.results(s.fm.searchID))
+
+	s.NotNil(s.fm.Scratch())
+	s.Nil(s.fm.cancelGrep)
+}
+
+func (s *SearchTestSuite) TestLeavingDirectoryCancels() {
+	s.fm.Grep("main")
+	id := s.fm.searchID
+
+	s.Require().NoError(s.fm.LoadDirectory("/other"))
+	s.Nil(s.fm.cancelGrep)
+
+	s.fm.finishSearch(s.results(id))
+	s.Nil(s.fm.Scratch())
+	s.Equal("/other", s.fm.DirectoryManager().CurrentPath())
+}
+
+func TestSearchSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
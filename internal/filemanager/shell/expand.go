@@ -0,0 +1,89 @@
+package shell
+
+import (
+	"strings"
+)
+
+// Context is what the placeholders of a command stand for
+type Context struct {
+	Entry     string   // entry under the cursor
+	Selection []string // selected entries, the entry under the cursor if none
+	Dir       string   // current directory
+}
+
+// Expand replaces the placeholders of command, quoting what they stand for:
+//
+//	%   the entry under the cursor
+//	%s  the selected entries, separated by spaces
+//	%d  the current directory
+//	%%  a literal %
+//
+// Directory entries lose their trailing slash so that "%.tgz" stays usable,
+// and entries starting with "-" get a "./" so they are not taken as options.
+func Expand(command string, ctx Context) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(command); i++ {
+		if command[i] != '%' {
+			sb.WriteByte(command[i])
+			continue
+		}
+
+		next := byte(0)
+		if i+1 < len(command) {
+			next = command[i+1]
+		}
+
+		switch next {
+		case '%':
+			sb.WriteByte('%')
+			i++
+		case 's':
+			selection := ctx.Selection
+			if len(selection) == 0 {
+				selection = []string{ctx.Entry}
+			}
+			quoted := make([]string, len(selection))
+			for j, entry := range selection {
+				quoted[j] = quoteEntry(entry)
+			}
+			sb.WriteString(strings.Join(quoted, " "))
+			i++
+		case 'd':
+			sb.WriteString(Quote(ctx.Dir))
+			i++
+		default:
+			sb.WriteString(quoteEntry(ctx.Entry))
+		}
+	}
+
+	return sb.String()
+}
+
+func quoteEntry(entry string) string {
+	entry = strings.TrimSuffix(entry, "/")
+	if strings.HasPrefix(entry, "-") {
+		entry = "./" + entry
+	}
+	return Quote(entry)
+}
+
+// Quote makes s a single word for a POSIX shell. Words made only of safe
+// characters are left as they are.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, unsafe) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func unsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("_-+=.,/:@", r)
+}
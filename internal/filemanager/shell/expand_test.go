@@ -0,0 +1,67 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExpandTestSuite struct {
+	suite.Suite
+}
+
+func (s *ExpandTestSuite) TestExpand() {
+	ctx := Context{
+		Entry:     "my file.txt",
+		Selection: []string{"a.txt", "b dir/"},
+		Dir:       "/home/me/it's",
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		ctx      Context
+		expected string
+	}{
+		{name: "entry", command: "wc -l %", ctx: ctx, expected: "wc -l 'my file.txt'"},
+		{name: "selection", command: "rm %s", ctx: ctx, expected: "rm a.txt 'b dir'"},
+		{name: "selection falls back to entry", command: "rm %s", ctx: Context{Entry: "x"}, expected: "rm x"},
+		{name: "directory", command: "du -sh %d", ctx: ctx, expected: `du -sh '/home/me/it'\''s'`},
+		{name: "literal percent", command: "date +%%s", ctx: ctx, expected: "date +%s"},
+		{name: "entry followed by text", command: "tar czf %.tgz %", ctx: Context{Entry: "src/"}, expected: "tar czf src.tgz src"},
+		{name: "trailing percent", command: "echo %", ctx: Context{Entry: "a"}, expected: "echo a"},
+		{name: "entry looking like an option", command: "rm %", ctx: Context{Entry: "-rf"}, expected: "rm ./-rf"},
+		{name: "no placeholders", command: "ls -la", ctx: ctx, expected: "ls -la"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.expected, Expand(tt.command, tt.ctx))
+		})
+	}
+}
+
+func (s *ExpandTestSuite) TestQuote() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain.txt", expected: "plain.txt"},
+		{input: "", expected: "''"},
+		{input: "two words", expected: "'two words'"},
+		{input: "it's", expected: `'it'\''s'`},
+		{input: "$HOME", expected: "'$HOME'"},
+		{input: "*.go", expected: "'*.go'"},
+		{input: "-rf", expected: "-rf"},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.input, func() {
+			s.Equal(tt.expected, Quote(tt.input))
+		})
+	}
+}
+
+func TestExpandSuite(t *testing.T) {
+	suite.Run(t, new(ExpandTestSuite))
+}
@@ -0,0 +1,114 @@
+package shell
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+)
+
+// scriptHeader stops at the first failing command and refuses to replace
+// existing targets, as executing the operations would without asking
+const scriptHeader = `#!/bin/sh
+# %d operation(s) exported by grease. Review before running.
+set -eu
+
+absent() {
+	if [ -e "$1" ] || [ -L "$1" ]; then
+		echo "$1 already exists" >&2
+		exit 1
+	fi
+}
+`
+
+// Script renders ops as a POSIX shell script doing the same as executing
+// them. Deleted directories are told apart from files through fs, as the
+// executor only removes empty ones. Created files are written with the
+// content of their template when templates is not nil.
+func Script(fs types.FileSystem, templates types.Templates, ops []types.Operation) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, scriptHeader, len(ops))
+
+	for _, op := range ops {
+		sb.WriteString("\n")
+		src := Quote(filepath.Clean(op.Source()))
+
+		switch op.Type() {
+		case types.Delete:
+			info, err := fs.Lstat(op.Source())
+			if err == nil && info.IsDir() {
+				fmt.Fprintf(&sb, "rmdir -- %s\n", src)
+			} else {
+				fmt.Fprintf(&sb, "rm -- %s\n", src)
+			}
+		case types.Rename:
+			dst := Quote(filepath.Clean(op.Target()))
+			fmt.Fprintf(&sb, "absent %s\nmv -- %s %s\n", dst, src, dst)
+		case types.Move:
+			dst := Quote(filepath.Join(op.Target(), filepath.Base(op.Source())))
+			fmt.Fprintf(&sb, "absent %s\nmv -- %s %s\n", dst, src, dst)
+		case types.Copy:
+			dst := Quote(filepath.Join(op.Target(), filepath.Base(op.Source())))
+			fmt.Fprintf(&sb, "absent %s\ncp -Rp -- %s %s\n", dst, src, dst)
+		case types.Create:
+			if strings.HasSuffix(op.Source(), "/") {
+				fmt.Fprintf(&sb, "absent %s\nmkdir -p -- %s\n", src, src)
+			} else if content := template(templates, op.Source()); len(content) > 0 {
+				fmt.Fprintf(&sb, "absent %s\nprintf '%%s' %s > %s\n", src, Quote(string(content)), src)
+			} else {
+				fmt.Fprintf(&sb, "absent %s\n: > %s\n", src, src)
+			}
+		case types.Pack:
+			dst := Quote(filepath.Clean(op.Target()))
+			dir := Quote(filepath.Dir(op.Source()))
+			var names []string
+			for _, source := range op.Sources() {
+				names = append(names, Quote("./"+filepath.Base(source)))
+			}
+			fmt.Fprintf(&sb, "absent %s\n", dst)
+			switch vfs.ArchiveFormat(op.Target()) {
+			case "zip":
+				fmt.Fprintf(&sb, "(cd %s && zip -qry %s %s)\n", dir, dst, strings.Join(names, " "))
+			case "tar.gz":
+				fmt.Fprintf(&sb, "tar -czf %s -C %s %s\n", dst, dir, strings.Join(names, " "))
+			case "tar":
+				fmt.Fprintf(&sb, "tar -cf %s -C %s %s\n", dst, dir, strings.Join(names, " "))
+			default:
+				return "", fmt.Errorf("cannot export %s", operation.Describe(op))
+			}
+		case types.Extract:
+			dst := Quote(filepath.Clean(op.Target()))
+			fmt.Fprintf(&sb, "absent %s\nmkdir -- %s\n", dst, dst)
+			switch vfs.ArchiveFormat(op.Source()) {
+			case "zip":
+				fmt.Fprintf(&sb, "unzip -q %s -d %s\n", src, dst)
+			case "tar.gz":
+				fmt.Fprintf(&sb, "tar -xzf %s -C %s\n", src, dst)
+			case "tar":
+				fmt.Fprintf(&sb, "tar -xf %s -C %s\n", src, dst)
+			default:
+				return "", fmt.Errorf("cannot export %s", operation.Describe(op))
+			}
+		default:
+			return "", fmt.Errorf("cannot export operation %v", op.Type())
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// template returns what the executor seeds a new file at path with. A
+// template that cannot be read leaves the file empty there as well.
+func template(templates types.Templates, path string) []byte {
+	if templates == nil {
+		return nil
+	}
+	content, _, err := templates.Content(path)
+	if err != nil {
+		return nil
+	}
+	return content
+}
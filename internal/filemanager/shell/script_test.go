@@ -0,0 +1,158 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/operation"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/filemanager/vfs"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScriptTestSuite struct {
+	suite.Suite
+	fs types.FileSystem
+}
+
+func (s *ScriptTestSuite) SetupTest() {
+	s.fs = vfs.NewMemory()
+	s.Require().NoError(s.fs.MkdirAll("/srv/empty", 0o755))
+	w, err := s.fs.Create("/srv/it's.txt", 0o644)
+	s.Require().NoError(err)
+	s.Require().NoError(w.Close())
+}
+
+func (s *ScriptTestSuite) TestScript() {
+	tests := []struct {
+		name     string
+		op       types.Operation
+		expected string
+	}{
+		{
+			name:     "delete file",
+			op:       operation.New(types.Delete, "/srv/it's.txt", ""),
+			expected: `rm -- '/srv/it'\''s.txt'`,
+		},
+		{
+			name:     "delete directory",
+			op:       operation.New(types.Delete, "/srv/empty", ""),
+			expected: "rmdir -- /srv/empty",
+		},
+		{
+			name:     "rename",
+			op:       operation.New(types.Rename, "/srv/a b", "/srv/c"),
+			expected: "absent /srv/c\nmv -- '/srv/a b' /srv/c",
+		},
+		{
+			name:     "move into directory",
+			op:       operation.New(types.Move, "/srv/a.txt", "/backup"),
+			expected: "absent /backup/a.txt\nmv -- /srv/a.txt /backup/a.txt",
+		},
+		{
+			name:     "copy into directory",
+			op:       operation.New(types.Copy, "/srv/dir", "/backup"),
+			expected: "absent /backup/dir\ncp -Rp -- /srv/dir /backup/dir",
+		},
+		{
+			name:     "create directory",
+			op:       operation.New(types.Create, "/srv/new/", ""),
+			expected: "absent /srv/new\nmkdir -p -- /srv/new",
+		},
+		{
+			name:     "create file",
+			op:       operation.New(types.Create, "/srv/$x", ""),
+			expected: "absent '/srv/$x'\n: > '/srv/$x'",
+		},
+		{
+			name:     "pack into zip",
+			op:       operation.NewPack([]string{"/srv/a b", "/srv/c"}, "/srv/out.zip"),
+			expected: "absent /srv/out.zip\n(cd /srv && zip -qry /srv/out.zip './a b' ./c)",
+		},
+		{
+			name:     "pack into tar.gz",
+			op:       operation.NewPack([]string{"/srv/dir"}, "/backup/dir.tgz"),
+			expected: "absent /backup/dir.tgz\ntar -czf /backup/dir.tgz -C /srv ./dir",
+		},
+		{
+			name:     "extract zip",
+			op:       operation.New(types.Extract, "/srv/in.zip", "/srv/in"),
+			expected: "absent /srv/in\nmkdir -- /srv/in\nunzip -q /srv/in.zip -d /srv/in",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			script, err := Script(s.fs, nil, []types.Operation{tt.op})
+			s.Require().NoError(err)
+
+			_, body, ok := strings.Cut(script, "}\n\n")
+			s.Require().True(ok, "script should start with its header")
+			s.Equal(tt.expected+"\n", body)
+		})
+	}
+}
+
+func (s *ScriptTestSuite) TestHeader() {
+	script, err := Script(s.fs, nil, []types.Operation{
+		operation.New(types.Delete, "/srv/a", ""),
+		operation.New(types.Delete, "/srv/b", ""),
+	})
+	s.Require().NoError(err)
+
+	s.True(strings.HasPrefix(script, "#!/bin/sh\n# 2 operation(s) exported by grease"))
+	s.Contains(script, "set -eu\n")
+}
+
+// fixedTemplates seeds every file with content, or fails with err
+type fixedTemplates struct {
+	content string
+	err     error
+}
+
+func (t fixedTemplates) Content(path string) ([]byte, bool, error) {
+	if t.err != nil {
+		return nil, false, t.err
+	}
+	return []byte(t.content), true, nil
+}
+
+func (s *ScriptTestSuite) TestCreateFromTemplate() {
+	tests := []struct {
+		name      string
+		templates types.Templates
+		expected  string
+	}{
+		{
+			name:      "template content",
+			templates: fixedTemplates{content: "it's\n"},
+			expected:  "absent /srv/new.txt\nprintf '%s' 'it'\\''s\n' > /srv/new.txt",
+		},
+		{
+			name:      "empty template",
+			templates: fixedTemplates{},
+			expected:  "absent /srv/new.txt\n: > /srv/new.txt",
+		},
+		{
+			name:      "unreadable template",
+			templates: fixedTemplates{err: errors.New("permission denied")},
+			expected:  "absent /srv/new.txt\n: > /srv/new.txt",
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			script, err := Script(s.fs, tt.templates, []types.Operation{operation.New(types.Create, "/srv/new.txt", "")})
+			s.Require().NoError(err)
+
+			_, body, ok := strings.Cut(script, "}\n\n")
+			s.Require().True(ok, "script should start with its header")
+			s.Equal(tt.expected+"\n", body)
+		})
+	}
+}
+
+func TestScriptSuite(t *testing.T) {
+	suite.Run(t, new(ScriptTestSuite))
+}
@@ -0,0 +1,97 @@
+package filemanager
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// sizeMsg carries the total of one entry, or that all are known once
+// done is set
+type sizeMsg struct {
+	dir     string
+	result  types.SizeResult
+	results <-chan types.SizeResult
+	done    bool
+}
+
+// ShowSizes shows the size of each entry next to it. Files have theirs
+// right away, directories are walked in the background and fill in as
+// their totals arrive. With fresh set cached totals are not used.
+func (fm *Filemanager) ShowSizes(fresh bool) {
+	if fm.scratch != nil {
+		fm.editor.SetMessage("Sizes are only shown for a directory listing", true)
+		return
+	}
+	fm.cancelSizeWalk()
+
+	dir := fm.dirManager.CurrentPath()
+	entries, err := fm.dirManager.ReadDirectory()
+	if err != nil {
+		fm.editor.SetMessage(fmt.Sprintf("Failed to read %s: %v", dir, err), true)
+		return
+	}
+
+	fm.entrySizes = make(map[string]string, len(entries))
+	fm.redecorate = true
+	var dirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, "/") {
+			fm.entrySizes[name] = "…"
+			dirs = append(dirs, strings.TrimSuffix(name, "/"))
+			continue
+		}
+		if info, err := fm.fs.Lstat(filepath.Join(dir, name)); err == nil {
+			fm.entrySizes[name] = formatBytes(info.Size())
+		}
+	}
+	if len(dirs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fm.cancelSizes = cancel
+	fm.editor.SetMessage(fmt.Sprintf("Calculating the size of %d directories…", len(dirs)), false)
+	fm.queueCmd(waitForSize(dir, fm.sizes.Sizes(ctx, dir, dirs, fresh)))
+}
+
+func waitForSize(dir string, results <-chan types.SizeResult) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-results
+		return sizeMsg{dir: dir, result: result, results: results, done: !ok}
+	}
+}
+
+func (fm *Filemanager) updateSize(msg sizeMsg) tea.Cmd {
+	if msg.dir != fm.dirManager.CurrentPath() || fm.entrySizes == nil {
+		return nil
+	}
+	if msg.done {
+		fm.cancelSizes = nil
+		fm.editor.SetMessage("", false)
+		return nil
+	}
+
+	name := msg.result.Name + "/"
+	if msg.result.Err != nil {
+		fm.logger.Println("Failed to calculate size of", msg.result.Name+":", msg.result.Err)
+		fm.entrySizes[name] = "?"
+	} else {
+		fm.entrySizes[name] = formatBytes(msg.result.Size)
+	}
+	fm.redecorate = true
+	return waitForSize(msg.dir, msg.results)
+}
+
+// cancelSizeWalk stops walking directories for ShowSizes
+func (fm *Filemanager) cancelSizeWalk() {
+	if fm.cancelSizes != nil {
+		fm.cancelSizes()
+		fm.cancelSizes = nil
+	}
+}
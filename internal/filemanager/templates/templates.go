@@ -0,0 +1,76 @@
+// Package templates seeds new files from files kept in a directory
+package templates
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// Dir holds one template per file. A template named after a file, such as
+// "Makefile" or "main.go", is used for files of exactly that name. One
+// named "*.ext" is used for any file ending in ".ext", the longest such
+// extension winning, so "*.test.js" is preferred over "*.js".
+//
+// Templates are read whenever a file is created, so edits to them apply
+// right away. These placeholders are replaced in their content:
+//
+//	{{date}}  today as 2006-01-02
+//	{{dir}}   the name of the directory the file is created in
+//	{{stem}}  the name of the file without its last extension
+type Dir struct {
+	dir string
+	now func() time.Time
+}
+
+func New(dir string) types.Templates {
+	return &Dir{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+func (d *Dir) Content(path string) ([]byte, bool, error) {
+	name := filepath.Base(path)
+	for _, candidate := range candidates(name) {
+		content, err := os.ReadFile(filepath.Join(d.dir, candidate))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return d.expand(content, path), true, nil
+	}
+	return nil, false, nil
+}
+
+// candidates lists the template names that apply to a file, best first:
+// the name itself, then "*" with each extension from the longest
+func candidates(name string) []string {
+	names := []string{name}
+	// A leading dot marks a hidden file, not an extension
+	for i := 1; i < len(name); i++ {
+		if name[i] == '.' && i < len(name)-1 {
+			names = append(names, "*"+name[i:])
+		}
+	}
+	return names
+}
+
+func (d *Dir) expand(content []byte, path string) []byte {
+	stem := filepath.Base(path)
+	if ext := filepath.Ext(stem); ext != stem {
+		stem = strings.TrimSuffix(stem, ext)
+	}
+	replacer := strings.NewReplacer(
+		"{{date}}", d.now().Format("2006-01-02"),
+		"{{dir}}", filepath.Base(filepath.Dir(path)),
+		"{{stem}}", stem,
+	)
+	return []byte(replacer.Replace(string(content)))
+}
@@ -0,0 +1,68 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TemplatesTestSuite struct {
+	suite.Suite
+	dir       string
+	templates *Dir
+}
+
+func (s *TemplatesTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.templates = New(s.dir).(*Dir)
+	s.templates.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+	for name, content := range map[string]string{
+		"Makefile":  "all:\n",
+		"main.go":   "package main\n",
+		"*.go":      "package {{dir}}\n// {{stem}}, {{date}}\n",
+		"*.js":      "// js\n",
+		"*.test.js": "test('{{stem}}')\n",
+	} {
+		s.Require().NoError(os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0644))
+	}
+}
+
+func (s *TemplatesTestSuite) TestContent() {
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"/src/Makefile", "all:\n", true},
+		{"/src/cmd/main.go", "package main\n", true},
+		{"/src/server/http.go", "package server\n// http, 2024-05-01\n", true},
+		{"/src/app.js", "// js\n", true},
+		{"/src/app.test.js", "test('app.test')\n", true},
+		{"/src/README.md", "", false},
+		{"/src/.go", "", false},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.path, func() {
+			content, ok, err := s.templates.Content(tt.path)
+			s.Require().NoError(err)
+			s.Equal(tt.ok, ok)
+			s.Equal(tt.want, string(content))
+		})
+	}
+}
+
+func (s *TemplatesTestSuite) TestMissingDir() {
+	content, ok, err := New(filepath.Join(s.dir, "missing")).Content("/src/main.go")
+	s.NoError(err)
+	s.False(ok)
+	s.Nil(content)
+}
+
+func TestTemplatesSuite(t *testing.T) {
+	suite.Run(t, new(TemplatesTestSuite))
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// AuditEntry records one executed operation
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Source string    `json:"source"`
+	Target string    `json:"target,omitempty"`
+	Result string    `json:"result"`
+	Error  string    `json:"error,omitempty"`
+	Cwd    string    `json:"cwd"`
+}
+
+// AuditLog keeps an append-only record of executed operations
+type AuditLog interface {
+	Record(entry AuditEntry) error
+	// Entries returns everything recorded, oldest first, including what
+	// was rotated out of the current file
+	Entries() ([]AuditEntry, error)
+}
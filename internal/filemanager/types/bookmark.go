@@ -0,0 +1,8 @@
+package types
+
+type BookmarkManager interface {
+	Set(mark rune, path string) error
+	Get(mark rune) (string, bool)
+	All() map[rune]string
+	Replace(bookmarks map[rune]string) error
+}
@@ -0,0 +1,9 @@
+package types
+
+// EntryColors picks the colour a directory entry is drawn in, following the
+// rules of LS_COLORS
+type EntryColors interface {
+	// Color returns the SGR sequence for the entry at path, such as "01;34",
+	// or "" when it is drawn plainly
+	Color(fs FileSystem, path string) string
+}
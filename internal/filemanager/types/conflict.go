@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSkipped is returned by an executor for an operation that was
+// deliberately not carried out
+var ErrSkipped = errors.New("skipped")
+
+// Conflict is an operation whose target already exists
+type Conflict struct {
+	Operation Operation
+	Target    string
+}
+
+// ConflictError is returned for a conflict that nobody resolved
+type ConflictError struct {
+	Conflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already exists", e.Target)
+}
+
+type ConflictResolution int
+
+const (
+	// ConflictAsk leaves the decision to the user
+	ConflictAsk ConflictResolution = iota
+	// ConflictOverwrite replaces the existing target
+	ConflictOverwrite
+	// ConflictSkip leaves both alone and skips the operation
+	ConflictSkip
+	// ConflictSuffix picks a free name such as "name (1).txt"
+	ConflictSuffix
+)
+
+// ConflictResolver decides what to do about a conflict. It may block, e.g.
+// until the user answered.
+type ConflictResolver func(Conflict) ConflictResolution
+
+// ParseConflictResolution reads "ask", "overwrite", "skip" or "suffix"
+func ParseConflictResolution(s string) (ConflictResolution, error) {
+	switch s {
+	case "ask":
+		return ConflictAsk, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "skip":
+		return ConflictSkip, nil
+	case "suffix":
+		return ConflictSuffix, nil
+	default:
+		return ConflictAsk, fmt.Errorf("unknown conflict resolution %q, expected ask, overwrite, skip or suffix", s)
+	}
+}
@@ -5,4 +5,6 @@ type DirectoryManager interface {
 	ChangeDirectory(path string) error
 	CurrentPath() string
 	GetDirectoryContent() ([]byte, error)
+	// IsReadOnly reports whether the current directory can be modified
+	IsReadOnly() bool
 }
@@ -7,8 +7,48 @@ import (
 
 type FileManager interface {
 	LoadDirectory(path string) error
+	Reveal(path string) error
+	// Open loads a directory or archive into the listing and opens a file
+	// in $EDITOR
+	Open(path string) error
+	OpenScratch(scratch Scratch) error
+	Scratch() Scratch
+	// Results returns the outcome of the operations of the last save
+	Results() []OperationResult
+	// RunShell runs command in the current directory in the background,
+	// shows its output and reloads the directory once it exits
+	RunShell(command string)
+	// QueueEdits queues the operations the edits of the listing amount to,
+	// as saving does before applying them
+	QueueEdits() error
+	// RunOperations executes ops in the background the way a save does,
+	// then reloads the directory or the open scratch
+	RunOperations(ops []Operation)
+	// ShowSizes shows the size of each entry next to it, walking
+	// directories in the background. With fresh set cached totals are not
+	// used.
+	ShowSizes(fresh bool)
+	// Grep lists the lines matching pattern in the files below the current
+	// directory, searching in the background
+	Grep(pattern string)
+	// Compare lists how the current directory differs from right,
+	// comparing the trees in the background
+	Compare(right string)
+	// Jump changes to the best ranked visited directory matching keywords,
+	// or lets one be chosen in the finder without keywords
+	Jump(keywords ...string) error
+	// ExpandPath returns path absolute against the current directory, with
+	// a leading "~" expanded to the home directory
+	ExpandPath(path string) (string, error)
+	// Picker returns what is picked when grease runs as a file chooser, or
+	// nil otherwise
+	Picker() Picker
+	// FinishPicking confirms the marked entries and quits
+	FinishPicking()
+	FileSystem() FileSystem
 	DirectoryManager() DirectoryManager
 	OperationManager() OperationManager
+	BookmarkManager() BookmarkManager
 	Editor() eTypes.Editor
 	Logger() Logger
 
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"io"
+	"io/fs"
+	"time"
+)
+
+// FileSystem is the storage the directory manager reads from and operations
+// are executed against. Paths are absolute and slash separated.
+type FileSystem interface {
+	ReadDir(path string) ([]fs.DirEntry, error)
+	Stat(path string) (fs.FileInfo, error)
+	Lstat(path string) (fs.FileInfo, error)
+	Open(path string) (io.ReadCloser, error)
+	Create(path string, perm fs.FileMode) (io.WriteCloser, error)
+	// Rename fails with syscall.EXDEV when the paths are on different
+	// devices
+	Rename(oldpath, newpath string) error
+	Remove(path string) error
+	RemoveAll(path string) error
+	Mkdir(path string, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+	Copy(src, dst string) error
+	Symlink(oldname, newname string) error
+	Readlink(path string) (string, error)
+	Chtimes(path string, atime, mtime time.Time) error
+	// Chmod sets the permission bits, unlike Create and Mkdir it is not
+	// subject to the umask
+	Chmod(path string, mode fs.FileMode) error
+	// ReadOnly reports whether path lies in a location that cannot be
+	// modified, such as the inside of an archive
+	ReadOnly(path string) bool
+}
@@ -0,0 +1,15 @@
+package types
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Finder is an overlay that fuzzy searches paths below a directory. While it
+// is active it receives all messages instead of the editor.
+type Finder interface {
+	Open(root string) tea.Cmd
+	// OpenPaths searches the given absolute paths instead
+	OpenPaths(paths []string) tea.Cmd
+	Close()
+	Active() bool
+	Update(msg tea.Msg) tea.Cmd
+	View(width, height int) string
+}
@@ -0,0 +1,12 @@
+package types
+
+// Frecency ranks directories by how often and how recently they were
+// visited
+type Frecency interface {
+	// Add records a visit of dir
+	Add(dir string) error
+	// Query returns the directories matching keywords, best first. The
+	// keywords have to appear in the path in order, the last one in its
+	// final element. Directories that no longer exist are dropped.
+	Query(keywords ...string) ([]string, error)
+}
@@ -3,5 +3,5 @@ package types
 import tea "github.com/charmbracelet/bubbletea"
 
 type Handler interface {
-	Handle(msg tea.KeyMsg) (tea.Cmd, error)
+	Handle(msg tea.KeyMsg) (bool, tea.Cmd, error)
 }
@@ -0,0 +1,6 @@
+package types
+
+// IgnoreMatcher decides which paths recursive walks such as the finder skip.
+type IgnoreMatcher interface {
+	Match(relPath string, isDir bool) bool
+}
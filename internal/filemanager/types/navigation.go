@@ -0,0 +1,13 @@
+package types
+
+// NavigationHistory is a jump list of visited directories that also
+// remembers which entry the cursor was on in each of them.
+type NavigationHistory interface {
+	Push(path string)
+	// Back and Forward return the entry to jump to. The history moves
+	// there once Push records the visit, after the directory loaded.
+	Back() (string, bool)
+	Forward() (string, bool)
+	SetCursor(dir string, entry string)
+	Cursor(dir string) (string, bool)
+}
@@ -1,12 +1,21 @@
 package types
 
+import (
+	"context"
+	"fmt"
+)
+
 type OperationQueue interface {
 	Push(op Operation)
 	Clear()
 	IsEmpty() bool
 	GetOperationDescriptions() []string
-	Execute() error
+	Execute(ctx context.Context, policy FailurePolicy, progress ProgressFunc, resolve ConflictResolver) []OperationResult
 	Operations() []Operation
+	// Skip drops the first queued operation
+	Skip() (Operation, bool)
+	// Remove drops op if it is still queued
+	Remove(op Operation) bool
 }
 
 type OperationType int
@@ -16,22 +25,156 @@ const (
 	Rename
 	Move
 	Create
+	Copy
+	// Pack writes its sources into a new archive, the format following the
+	// extension of the target
+	Pack
+	// Extract unpacks the source archive into the target directory, which
+	// it creates
+	Extract
 )
 
+func (t OperationType) String() string {
+	switch t {
+	case Delete:
+		return "delete"
+	case Rename:
+		return "rename"
+	case Move:
+		return "move"
+	case Create:
+		return "create"
+	case Copy:
+		return "copy"
+	case Pack:
+		return "pack"
+	case Extract:
+		return "extract"
+	default:
+		return fmt.Sprintf("operation(%d)", int(t))
+	}
+}
+
 type Operation interface {
 	Type() OperationType
+	// Source is the entry the operation acts on, the first one for a Pack
 	Source() string
+	// Sources lists every entry the operation reads, more than one only
+	// for a Pack
+	Sources() []string
 	Target() string
 }
 
 type OperationManager interface {
 	QueueOperation(op Operation)
-	ExecuteOperations() error
+	// ExecuteOperations runs the queued operations in order, reporting to
+	// progress and asking resolve about existing targets when they are set.
+	// What happens after a failure depends on the failure policy, with
+	// AskOnFailure the failed operation and all following ones stay queued.
+	// Once ctx is done the running operation fails and the rest are
+	// skipped.
+	ExecuteOperations(ctx context.Context, progress ProgressFunc, resolve ConflictResolver) []OperationResult
+	// SkipOperation drops the first queued operation, typically the one
+	// that failed, and reports it as skipped
+	SkipOperation() (OperationResult, bool)
+	// AbortOperations drops the failed operation and the rest of its
+	// batch, reporting the rest as skipped. Operations queued while the
+	// batch ran stay queued.
+	AbortOperations() []OperationResult
+	FailurePolicy() FailurePolicy
+	// ConflictResolution is how conflicts are resolved without asking,
+	// ConflictAsk when the user decides
+	ConflictResolution() ConflictResolution
+	// Templates seed the files Create operations make, nil when they start
+	// out empty
+	Templates() Templates
+	// AuditLog is where executed operations are recorded, nil when they
+	// are not
+	AuditLog() AuditLog
 	GetPendingOperations() []Operation
+	// RemoveOperation drops op if it is still queued, operations queued
+	// around it keep their order
+	RemoveOperation(op Operation) bool
 	Clear()
 }
 
+type OperationStatus int
+
+const (
+	OperationSucceeded OperationStatus = iota
+	OperationSkipped
+	OperationFailed
+)
+
+func (s OperationStatus) String() string {
+	switch s {
+	case OperationSucceeded:
+		return "ok"
+	case OperationSkipped:
+		return "skipped"
+	case OperationFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// OperationResult is the outcome of one executed operation. Err is set for
+// failed operations.
+type OperationResult struct {
+	Operation Operation
+	Status    OperationStatus
+	Err       error
+}
+
+// FailurePolicy decides what happens to the remaining operations once one
+// fails
+type FailurePolicy int
+
+const (
+	// StopOnFailure skips everything after the failed operation
+	StopOnFailure FailurePolicy = iota
+	// SkipOnFailure carries on with the next operation
+	SkipOnFailure
+	// AskOnFailure pauses with the failed operation still queued
+	AskOnFailure
+)
+
 type OperationExecutor interface {
-	Execute(op Operation) error
+	// Execute runs op. Operations that copy data, such as Copy or a move
+	// between devices, report to progress when it is set and stop once ctx
+	// is done. When the target exists resolve decides what happens, without
+	// it a *ConflictError is returned. ErrSkipped is returned when op was
+	// skipped on purpose.
+	Execute(ctx context.Context, op Operation, progress ProgressFunc, resolve ConflictResolver) error
 	ValidateOperation(op Operation) error
 }
+
+// Progress is how far a batch of operations got. Step counts operations
+// from 1, the byte and file counts are those of the running operation.
+type Progress struct {
+	Operation  Operation
+	Step       int
+	Steps      int
+	Path       string // file being copied
+	Bytes      int64
+	TotalBytes int64
+	Files      int
+	TotalFiles int
+}
+
+type ProgressFunc func(Progress)
+
+// ParseFailurePolicy reads "stop", "skip" or "ask"
+func ParseFailurePolicy(s string) (FailurePolicy, error) {
+	switch s {
+	case "stop":
+		return StopOnFailure, nil
+	case "skip":
+		return SkipOnFailure, nil
+	case "ask":
+		return AskOnFailure, nil
+	default:
+		return StopOnFailure, fmt.Errorf("unknown failure policy %q, expected stop, skip or ask", s)
+	}
+}
@@ -0,0 +1,43 @@
+package types
+
+import "fmt"
+
+// PickKind limits which entries can be picked
+type PickKind int
+
+const (
+	PickAny PickKind = iota
+	PickFiles
+	PickDirs
+)
+
+// Picker collects the entries chosen when grease runs as a file chooser for
+// another program
+type Picker interface {
+	// Within reports whether dir may be navigated to
+	Within(dir string) bool
+	// Toggle marks the entry at path, or unmarks it when it was marked
+	// already. Entries of a kind that cannot be picked are refused.
+	Toggle(path string, isDir bool) error
+	Marked(path string) bool
+	// Marks returns the marked paths in the order they were marked
+	Marks() []string
+	// Confirm ends picking with the marked paths
+	Confirm()
+	// Picked returns the marked paths once picking was confirmed
+	Picked() ([]string, bool)
+}
+
+// ParsePickKind reads "any", "files" or "dirs"
+func ParsePickKind(s string) (PickKind, error) {
+	switch s {
+	case "any":
+		return PickAny, nil
+	case "files":
+		return PickFiles, nil
+	case "dirs":
+		return PickDirs, nil
+	default:
+		return PickAny, fmt.Errorf("unknown pick kind %q, expected any, files or dirs", s)
+	}
+}
@@ -0,0 +1,20 @@
+package types
+
+// Scratch is a non-directory listing shown in the editor buffer, such as the
+// bookmark list. While a scratch is open no file operations are queued.
+type Scratch interface {
+	Name() string
+	Lines() ([]string, error)
+	// Open is called when enter is pressed on a line of the scratch.
+	Open(line string) error
+	// Write is called on :w with the edited lines.
+	Write(lines []string) error
+}
+
+// KeyedScratch is a scratch that also acts on keys other than enter
+type KeyedScratch interface {
+	Scratch
+	// Key is called for keys pressed in normal mode with the line under the
+	// cursor. It reports whether the key was used.
+	Key(key, line string) (bool, error)
+}
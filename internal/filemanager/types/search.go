@@ -0,0 +1,23 @@
+package types
+
+import (
+	"context"
+	"regexp"
+)
+
+// SearchMatch is a line matching a search. Path is relative to the
+// directory searched, Line starts at 1.
+type SearchMatch struct {
+	Path string
+	Line int
+	Text string
+}
+
+// Searcher looks for lines matching a pattern in the files below a
+// directory
+type Searcher interface {
+	// Search walks root in the background and sends every matching line.
+	// The channel is closed when all files are searched or ctx is
+	// cancelled.
+	Search(ctx context.Context, root string, pattern *regexp.Regexp) <-chan SearchMatch
+}
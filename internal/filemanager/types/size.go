@@ -0,0 +1,19 @@
+package types
+
+import "context"
+
+// SizeResult is the total size of an entry, or why it could not be
+// worked out
+type SizeResult struct {
+	Name string
+	Size int64
+	Err  error
+}
+
+// SizeCalculator adds up the size of the files below directories
+type SizeCalculator interface {
+	// Sizes walks the named entries of dir in the background and sends the
+	// total of each once it is known. The channel is closed when all are
+	// done or ctx is cancelled. With fresh set cached totals are ignored.
+	Sizes(ctx context.Context, dir string, names []string, fresh bool) <-chan SizeResult
+}
@@ -0,0 +1,8 @@
+package types
+
+// Templates seed the files a Create operation makes
+type Templates interface {
+	// Content returns what a new file at path starts with, or false when
+	// no template matches it
+	Content(path string) ([]byte, bool, error)
+}
@@ -0,0 +1,290 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+var errUnsafePath = errors.New("entry escapes the archive")
+
+// IsArchive reports whether name has the extension of an archive format
+// that can be browsed as a directory
+func IsArchive(name string) bool {
+	return ArchiveFormat(name) != ""
+}
+
+// ArchiveFormat names the format of an archive by its extension: "zip",
+// "tar" or "tar.gz", empty for anything else
+func ArchiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// loadArchive reads the archive at archivePath from src into a memory file
+// system, placing its entries below archivePath itself
+func loadArchive(src types.FileSystem, archivePath string) (types.FileSystem, error) {
+	mem := NewMemory()
+	if err := mem.MkdirAll(archivePath, 0755); err != nil {
+		return nil, err
+	}
+
+	u := newUnpacker(context.Background(), mem, archivePath, nil)
+	if err := u.unpack(src, archivePath); err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+	return mem, nil
+}
+
+// unpacker writes the members of an archive below root as they are read,
+// skipping those that would end up outside of it: names leaving root,
+// symlinks pointing out of it, and members below a symlink the archive
+// created. Modes and modification times are kept.
+type unpacker struct {
+	ctx      context.Context
+	fsys     types.FileSystem
+	root     string
+	progress CopyProgress
+	dirs     map[string]fs.FileInfo // set once their members are in
+}
+
+func newUnpacker(ctx context.Context, fsys types.FileSystem, root string, progress CopyProgress) *unpacker {
+	return &unpacker{
+		ctx:      ctx,
+		fsys:     fsys,
+		root:     root,
+		progress: progress,
+		dirs:     make(map[string]fs.FileInfo),
+	}
+}
+
+// unpack reads the archive at archivePath on src
+func (u *unpacker) unpack(src types.FileSystem, archivePath string) error {
+	f, err := src.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch ArchiveFormat(archivePath) {
+	case "zip":
+		err = u.unzip(src, archivePath, f)
+	case "tar":
+		err = u.untar(f)
+	case "tar.gz":
+		var gz *gzip.Reader
+		if gz, err = gzip.NewReader(f); err == nil {
+			err = u.untar(gz)
+		}
+	default:
+		err = fmt.Errorf("unsupported archive format")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Directories were kept writable and touched by their members
+	for dir, info := range u.dirs {
+		if err := u.fsys.Chmod(dir, info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := u.fsys.Chtimes(dir, info.ModTime(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *unpacker) unzip(src types.FileSystem, archivePath string, f io.Reader) error {
+	// zip needs random access, archives that cannot be read at an offset
+	// such as those in memory are read in full
+	var r io.ReaderAt
+	var size int64
+	if ra, ok := f.(io.ReaderAt); ok {
+		info, err := src.Stat(archivePath)
+		if err != nil {
+			return err
+		}
+		r, size = ra, info.Size()
+	} else {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		if err := u.add(file.Name, file.FileInfo(), file.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *unpacker) untar(r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			if err := u.addSymlink(header.Name, header.Linkname); err != nil {
+				return err
+			}
+			continue
+		case tar.TypeDir, tar.TypeReg:
+		default:
+			// Hard links, devices and fifos have no place in a listing
+			continue
+		}
+
+		if err := u.add(header.Name, header.FileInfo(), func() (io.ReadCloser, error) {
+			return io.NopCloser(tr), nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// entryPath maps an archive member name below root, rejecting names that
+// would leave it
+func entryPath(root, name string) (string, error) {
+	cleaned := path.Clean("/" + filepath.ToSlash(name))
+	if cleaned == "/" || strings.Contains(name, "\x00") {
+		return "", errUnsafePath
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", errUnsafePath
+		}
+	}
+	return filepath.Join(root, filepath.FromSlash(cleaned)), nil
+}
+
+func (u *unpacker) add(name string, info fs.FileInfo, open func() (io.ReadCloser, error)) error {
+	if err := u.ctx.Err(); err != nil {
+		return err
+	}
+	target, err := entryPath(u.root, name)
+	if err != nil || !u.direct(target) {
+		// Skip the entry rather than refusing the whole archive
+		return nil
+	}
+
+	if err := u.fsys.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	mode := info.Mode()
+	if mode.IsDir() {
+		u.dirs[target] = info
+		return u.fsys.MkdirAll(target, mode.Perm()|0700)
+	}
+
+	in, err := open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if mode&fs.ModeSymlink != 0 {
+		link, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		return u.addSymlink(name, string(link))
+	}
+
+	out, err := u.fsys.Create(target, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, &progressReader{ctx: u.ctx, r: in, path: target, progress: u.progress}); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if u.progress != nil {
+		u.progress(target, 0, true)
+	}
+
+	// Create is subject to the umask
+	if err := u.fsys.Chmod(target, mode.Perm()); err != nil {
+		return err
+	}
+	return u.fsys.Chtimes(target, info.ModTime(), info.ModTime())
+}
+
+func (u *unpacker) addSymlink(name, link string) error {
+	target, err := entryPath(u.root, name)
+	if err != nil || !u.direct(target) || !u.inside(filepath.Dir(target), link) {
+		return nil
+	}
+	if err := u.fsys.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return u.fsys.Symlink(link, target)
+}
+
+// direct reports whether target and the directories leading to it below
+// root are no symlinks, so writing there cannot end up elsewhere
+func (u *unpacker) direct(target string) bool {
+	rel, err := filepath.Rel(u.root, target)
+	if err != nil {
+		return false
+	}
+	current := u.root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := u.fsys.Lstat(current)
+		if err != nil {
+			// Nothing below a missing entry exists yet
+			return errors.Is(err, fs.ErrNotExist)
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// inside reports whether the symlink target link, relative to dir, stays
+// below root
+func (u *unpacker) inside(dir, link string) bool {
+	if link == "" || filepath.IsAbs(link) || strings.Contains(link, "\x00") {
+		return false
+	}
+	rel, err := filepath.Rel(u.root, filepath.Join(dir, filepath.FromSlash(link)))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
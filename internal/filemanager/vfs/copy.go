@@ -0,0 +1,246 @@
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// copyTree copies src to dst within fsys. Directories are copied
+// recursively, symlinks are recreated rather than followed and file modes
+// and modification times are preserved. dst must not exist.
+func copyTree(fsys types.FileSystem, src, dst string) error {
+	return CopyTree(context.Background(), fsys, fsys, src, dst, nil)
+}
+
+// copyBetween is copyTree from one file system to another
+func copyBetween(from, to types.FileSystem, src, dst string) error {
+	return CopyTree(context.Background(), from, to, src, dst, nil)
+}
+
+// CopyProgress is told about the bytes just written to the file at path,
+// and once more with done set when the file is complete
+type CopyProgress func(path string, written int64, done bool)
+
+// CopyTree copies src on from to dst on to like copyTree, reporting to
+// progress when set. It stops with the context's error once ctx is done,
+// leaving what was copied so far in place.
+func CopyTree(ctx context.Context, from, to types.FileSystem, src, dst string, progress CopyProgress) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := to.Lstat(dst); err == nil {
+		return &fs.PathError{Op: "copy", Path: dst, Err: fs.ErrExist}
+	}
+
+	info, err := from.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := from.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return to.Symlink(target, dst)
+
+	case info.IsDir():
+		// Writable until the children are in, the mode is set at the end
+		if err := to.Mkdir(dst, info.Mode().Perm()|0700); err != nil {
+			return err
+		}
+		entries, err := from.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := CopyTree(ctx, from, to, filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), progress); err != nil {
+				return err
+			}
+		}
+		// Set last, creating the children touched the directory. Chmod
+		// because Mkdir is subject to the umask.
+		if err := to.Chmod(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return to.Chtimes(dst, info.ModTime(), info.ModTime())
+
+	case info.Mode().IsRegular():
+		if err := copyFile(ctx, from, to, src, dst, info.Mode().Perm(), progress); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(src, 0, true)
+		}
+		// Create is subject to the umask too
+		if err := to.Chmod(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return to.Chtimes(dst, info.ModTime(), info.ModTime())
+
+	default:
+		return fmt.Errorf("cannot copy %s: unsupported file type %s", src, info.Mode().Type())
+	}
+}
+
+func copyFile(ctx context.Context, from, to types.FileSystem, src, dst string, perm fs.FileMode, progress CopyProgress) error {
+	in, err := from.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := to.Create(dst, perm)
+	if err != nil {
+		return err
+	}
+
+	w := &progressWriter{ctx: ctx, w: out, path: src, progress: progress}
+	if _, err := io.Copy(w, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// progressWriter reports writes and gives up once its context is done
+type progressWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	path     string
+	progress CopyProgress
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.w.Write(b)
+	if p.progress != nil {
+		p.progress(p.path, int64(n), false)
+	}
+	return n, err
+}
+
+// TreeSize is the total size and number of the regular files below path,
+// symlinks are not followed
+func TreeSize(fsys types.FileSystem, path string) (int64, int, error) {
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !info.IsDir() {
+		if info.Mode().IsRegular() {
+			return info.Size(), 1, nil
+		}
+		return 0, 0, nil
+	}
+
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	var files int
+	for _, entry := range entries {
+		s, f, err := TreeSize(fsys, filepath.Join(path, entry.Name()))
+		if err != nil {
+			return 0, 0, err
+		}
+		size += s
+		files += f
+	}
+	return size, files, nil
+}
+
+// CompareTree checks that b on fb is an exact copy of a on fa: the same
+// entries with the same types, permissions, link targets and file contents
+func CompareTree(fa, fb types.FileSystem, a, b string) error {
+	infoA, err := fa.Lstat(a)
+	if err != nil {
+		return err
+	}
+	infoB, err := fb.Lstat(b)
+	if err != nil {
+		return err
+	}
+
+	if infoA.Mode().Type() != infoB.Mode().Type() {
+		return fmt.Errorf("%s: type differs from %s", b, a)
+	}
+	if infoA.Mode()&fs.ModeSymlink == 0 && infoA.Mode().Perm() != infoB.Mode().Perm() {
+		return fmt.Errorf("%s: permissions differ from %s", b, a)
+	}
+
+	switch {
+	case infoA.Mode()&fs.ModeSymlink != 0:
+		targetA, err := fa.Readlink(a)
+		if err != nil {
+			return err
+		}
+		targetB, err := fb.Readlink(b)
+		if err != nil {
+			return err
+		}
+		if targetA != targetB {
+			return fmt.Errorf("%s: link target differs from %s", b, a)
+		}
+
+	case infoA.IsDir():
+		entriesA, err := fa.ReadDir(a)
+		if err != nil {
+			return err
+		}
+		entriesB, err := fb.ReadDir(b)
+		if err != nil {
+			return err
+		}
+		if len(entriesA) != len(entriesB) {
+			return fmt.Errorf("%s: has %d entries, %s has %d", b, len(entriesB), a, len(entriesA))
+		}
+		for _, entry := range entriesA {
+			if err := CompareTree(fa, fb, filepath.Join(a, entry.Name()), filepath.Join(b, entry.Name())); err != nil {
+				return err
+			}
+		}
+
+	case infoA.Mode().IsRegular():
+		if infoA.Size() != infoB.Size() {
+			return fmt.Errorf("%s: size differs from %s", b, a)
+		}
+		sumA, err := checksum(fa, a)
+		if err != nil {
+			return err
+		}
+		sumB, err := checksum(fb, b)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(sumA, sumB) {
+			return fmt.Errorf("%s: content differs from %s", b, a)
+		}
+	}
+	return nil
+}
+
+func checksum(fsys types.FileSystem, path string) ([]byte, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,130 @@
+package vfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CopyTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (s *CopyTestSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+}
+
+// Create and Mkdir go through the umask on the OS file system, modes that
+// it would clear must still be copied
+func (s *CopyTestSuite) TestKeepsModesOnOS() {
+	src := filepath.Join(s.dir, "src")
+	s.Require().NoError(os.Mkdir(src, 0755))
+	s.Require().NoError(os.Chmod(src, 0777))
+	s.Require().NoError(os.WriteFile(filepath.Join(src, "shared.txt"), []byte("shared"), 0644))
+	s.Require().NoError(os.Chmod(filepath.Join(src, "shared.txt"), 0666))
+	s.Require().NoError(os.Mkdir(filepath.Join(src, "locked"), 0755))
+	s.Require().NoError(os.WriteFile(filepath.Join(src, "locked", "a.txt"), []byte("a"), 0600))
+	s.Require().NoError(os.Chmod(filepath.Join(src, "locked"), 0555))
+	s.T().Cleanup(func() { os.Chmod(filepath.Join(src, "locked"), 0755) })
+
+	fsys := NewOS()
+	dst := filepath.Join(s.dir, "dst")
+	s.Require().NoError(CopyTree(context.Background(), fsys, fsys, src, dst, nil))
+	s.T().Cleanup(func() { os.Chmod(filepath.Join(dst, "locked"), 0755) })
+
+	s.NoError(CompareTree(fsys, fsys, src, dst))
+	for path, want := range map[string]os.FileMode{
+		"":             0777,
+		"shared.txt":   0666,
+		"locked":       0555,
+		"locked/a.txt": 0600,
+	} {
+		info, err := os.Stat(filepath.Join(dst, path))
+		s.Require().NoError(err)
+		s.Equal(want, info.Mode().Perm(), path)
+	}
+}
+
+func (s *CopyTestSuite) TestExtractKeepsModesOnOS() {
+	src := filepath.Join(s.dir, "src")
+	s.Require().NoError(os.MkdirAll(filepath.Join(src, "shared"), 0755))
+	s.Require().NoError(os.Chmod(filepath.Join(src, "shared"), 0777))
+	s.Require().NoError(os.WriteFile(filepath.Join(src, "shared", "notes.txt"), []byte("notes"), 0644))
+	s.Require().NoError(os.Chmod(filepath.Join(src, "shared", "notes.txt"), 0666))
+
+	fsys := NewOS()
+	for _, name := range []string{"src.zip", "src.tar.gz"} {
+		s.Run(name, func() {
+			archive := filepath.Join(s.dir, name)
+			dst := filepath.Join(s.dir, "out-"+name)
+			s.Require().NoError(WriteArchive(context.Background(), fsys, archive, []string{src}, nil))
+			s.Require().NoError(ExtractArchive(context.Background(), fsys, archive, dst, nil))
+			s.NoError(CompareTree(fsys, fsys, src, filepath.Join(dst, "src")))
+		})
+	}
+}
+
+// Members must not be written through symlinks the archive itself created
+func (s *CopyTestSuite) TestExtractStaysInside() {
+	outside := filepath.Join(s.dir, "outside")
+	s.Require().NoError(os.Mkdir(outside, 0755))
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range []*tar.Header{
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outside},
+		{Name: "up", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+		{Name: "self", Typeflag: tar.TypeSymlink, Linkname: "link"},
+		{Name: "link/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		{Name: "up/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+		{Name: "self/pwned", Typeflag: tar.TypeReg, Mode: 0644, Size: 5},
+	} {
+		s.Require().NoError(tw.WriteHeader(header))
+		if header.Size > 0 {
+			_, err := tw.Write([]byte("owned"))
+			s.Require().NoError(err)
+		}
+	}
+	s.Require().NoError(tw.Close())
+	archive := filepath.Join(s.dir, "evil.tar")
+	s.Require().NoError(os.WriteFile(archive, buf.Bytes(), 0644))
+
+	dst := filepath.Join(s.dir, "dst")
+	s.Require().NoError(ExtractArchive(context.Background(), NewOS(), archive, dst, nil))
+
+	entries, err := os.ReadDir(outside)
+	s.Require().NoError(err)
+	s.Empty(entries)
+	// The links leaving dst are skipped, the members below them end up in
+	// plain directories
+	for _, name := range []string{"link", "up"} {
+		info, err := os.Lstat(filepath.Join(dst, name))
+		s.Require().NoError(err)
+		s.True(info.IsDir(), name)
+	}
+	// A link staying inside is kept, nothing is written through it
+	target, err := os.Readlink(filepath.Join(dst, "self"))
+	s.Require().NoError(err)
+	s.Equal("link", target)
+}
+
+func (s *CopyTestSuite) TestKeepsModesOnMemory() {
+	fsys := NewMemory()
+	s.Require().NoError(fsys.MkdirAll("/src/dir", 0777))
+	f, err := fsys.Create("/src/dir/run.sh", 0755)
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+
+	s.Require().NoError(CopyTree(context.Background(), fsys, fsys, "/src", "/dst", nil))
+	s.NoError(CompareTree(fsys, fsys, "/src", "/dst"))
+}
+
+func TestCopySuite(t *testing.T) {
+	suite.Run(t, new(CopyTestSuite))
+}
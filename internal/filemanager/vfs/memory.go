@@ -0,0 +1,381 @@
+package vfs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+const maxSymlinkDepth = 40
+
+var errNotEmpty = errors.New("directory not empty")
+
+type node struct {
+	mode    fs.FileMode
+	data    []byte
+	target  string // symlink target
+	modTime time.Time
+}
+
+// Memory is a file system held entirely in memory. Relative paths are
+// resolved against "/".
+type Memory struct {
+	mu    sync.RWMutex
+	nodes map[string]*node
+}
+
+func NewMemory() types.FileSystem {
+	return &Memory{
+		nodes: map[string]*node{
+			"/": {mode: fs.ModeDir | 0755, modTime: time.Now()},
+		},
+	}
+}
+
+func (m *Memory) ReadDir(path string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path, n, err := m.resolve(path, true)
+	if err != nil {
+		return nil, pathError("readdir", path, err)
+	}
+	if !n.mode.IsDir() {
+		return nil, pathError("readdir", path, errors.New("not a directory"))
+	}
+
+	var entries []fs.DirEntry
+	for p, child := range m.nodes {
+		if p != "/" && filepath.Dir(p) == path {
+			entries = append(entries, fs.FileInfoToDirEntry(newFileInfo(p, child)))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (m *Memory) Stat(path string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resolved, n, err := m.resolve(path, true)
+	if err != nil {
+		return nil, pathError("stat", path, err)
+	}
+	return newFileInfo(resolved, n), nil
+}
+
+func (m *Memory) Lstat(path string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path = clean(path)
+	n, ok := m.nodes[path]
+	if !ok {
+		return nil, pathError("lstat", path, fs.ErrNotExist)
+	}
+	return newFileInfo(path, n), nil
+}
+
+func (m *Memory) Open(path string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, n, err := m.resolve(path, true)
+	if err != nil {
+		return nil, pathError("open", path, err)
+	}
+	if n.mode.IsDir() {
+		return nil, pathError("open", path, errors.New("is a directory"))
+	}
+	return io.NopCloser(bytes.NewReader(n.data)), nil
+}
+
+func (m *Memory) Create(path string, perm fs.FileMode) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = clean(path)
+	if err := m.checkParent(path); err != nil {
+		return nil, pathError("create", path, err)
+	}
+	if n, ok := m.nodes[path]; ok && n.mode.IsDir() {
+		return nil, pathError("create", path, errors.New("is a directory"))
+	}
+
+	m.nodes[path] = &node{mode: perm.Perm(), modTime: time.Now()}
+	return &memoryWriter{fs: m, path: path}, nil
+}
+
+func (m *Memory) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = clean(oldpath), clean(newpath)
+	n, ok := m.nodes[oldpath]
+	if !ok {
+		return pathError("rename", oldpath, fs.ErrNotExist)
+	}
+	if err := m.checkParent(newpath); err != nil {
+		return pathError("rename", newpath, err)
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	if n.mode.IsDir() && strings.HasPrefix(newpath, oldpath+"/") {
+		return pathError("rename", newpath, errors.New("cannot move a directory into itself"))
+	}
+	if existing, ok := m.nodes[newpath]; ok {
+		if existing.mode.IsDir() && m.hasChildren(newpath) {
+			return pathError("rename", newpath, errNotEmpty)
+		}
+		if existing.mode.IsDir() != n.mode.IsDir() {
+			return pathError("rename", newpath, fs.ErrExist)
+		}
+	}
+
+	moved := make(map[string]*node)
+	for p, child := range m.nodes {
+		if p == oldpath || strings.HasPrefix(p, oldpath+"/") {
+			moved[newpath+strings.TrimPrefix(p, oldpath)] = child
+			delete(m.nodes, p)
+		}
+	}
+	for p, child := range moved {
+		m.nodes[p] = child
+	}
+	return nil
+}
+
+func (m *Memory) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = clean(path)
+	if _, ok := m.nodes[path]; !ok {
+		return pathError("remove", path, fs.ErrNotExist)
+	}
+	if m.hasChildren(path) {
+		return pathError("remove", path, errNotEmpty)
+	}
+	delete(m.nodes, path)
+	return nil
+}
+
+func (m *Memory) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = clean(path)
+	for p := range m.nodes {
+		if p != "/" && (p == path || strings.HasPrefix(p, path+"/")) {
+			delete(m.nodes, p)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Mkdir(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = clean(path)
+	if _, ok := m.nodes[path]; ok {
+		return pathError("mkdir", path, fs.ErrExist)
+	}
+	if err := m.checkParent(path); err != nil {
+		return pathError("mkdir", path, err)
+	}
+
+	m.nodes[path] = &node{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = clean(path)
+	var missing []string
+	for p := path; ; p = filepath.Dir(p) {
+		if n, ok := m.nodes[p]; ok {
+			if !n.mode.IsDir() {
+				return pathError("mkdir", p, errors.New("not a directory"))
+			}
+			break
+		}
+		missing = append(missing, p)
+	}
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		m.nodes[missing[i]] = &node{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+func (m *Memory) Copy(src, dst string) error {
+	return copyTree(m, src, dst)
+}
+
+func (m *Memory) Symlink(oldname, newname string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	newname = clean(newname)
+	if _, ok := m.nodes[newname]; ok {
+		return pathError("symlink", newname, fs.ErrExist)
+	}
+	if err := m.checkParent(newname); err != nil {
+		return pathError("symlink", newname, err)
+	}
+
+	m.nodes[newname] = &node{mode: fs.ModeSymlink | 0777, target: oldname, modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) Readlink(path string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path = clean(path)
+	n, ok := m.nodes[path]
+	if !ok {
+		return "", pathError("readlink", path, fs.ErrNotExist)
+	}
+	if n.mode&fs.ModeSymlink == 0 {
+		return "", pathError("readlink", path, fs.ErrInvalid)
+	}
+	return n.target, nil
+}
+
+// Chtimes sets the modification time, access times are not kept
+func (m *Memory) Chtimes(path string, atime, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, n, err := m.resolve(path, true)
+	if err != nil {
+		return pathError("chtimes", path, err)
+	}
+	n.modTime = mtime
+	return nil
+}
+
+// Chmod sets the permission bits, other mode bits are kept
+func (m *Memory) Chmod(path string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, n, err := m.resolve(path, true)
+	if err != nil {
+		return pathError("chmod", path, err)
+	}
+	n.mode = n.mode&^fs.ModePerm | mode.Perm()
+	return nil
+}
+
+func (m *Memory) ReadOnly(path string) bool {
+	return false
+}
+
+// resolve looks up path, following symlinks in the final element when
+// follow is set. Callers must hold the lock.
+func (m *Memory) resolve(path string, follow bool) (string, *node, error) {
+	path = clean(path)
+	for depth := 0; depth < maxSymlinkDepth; depth++ {
+		n, ok := m.nodes[path]
+		if !ok {
+			return path, nil, fs.ErrNotExist
+		}
+		if !follow || n.mode&fs.ModeSymlink == 0 {
+			return path, n, nil
+		}
+
+		target := n.target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = clean(target)
+	}
+	return path, nil, errors.New("too many levels of symbolic links")
+}
+
+// checkParent verifies that the parent of path is an existing directory.
+// Callers must hold the lock.
+func (m *Memory) checkParent(path string) error {
+	_, parent, err := m.resolve(filepath.Dir(path), true)
+	if err != nil {
+		return err
+	}
+	if !parent.mode.IsDir() {
+		return errors.New("not a directory")
+	}
+	return nil
+}
+
+func (m *Memory) hasChildren(path string) bool {
+	for p := range m.nodes {
+		if p != path && strings.HasPrefix(p, strings.TrimSuffix(path, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func clean(path string) string {
+	if !filepath.IsAbs(path) {
+		path = "/" + path
+	}
+	return filepath.Clean(path)
+}
+
+func pathError(op, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+type memoryWriter struct {
+	fs   *Memory
+	path string
+	buf  bytes.Buffer
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+
+	n, ok := w.fs.nodes[w.path]
+	if !ok {
+		return pathError("close", w.path, fs.ErrNotExist)
+	}
+	n.data = w.buf.Bytes()
+	n.modTime = time.Now()
+	return nil
+}
+
+type fileInfo struct {
+	name string
+	node *node
+}
+
+func newFileInfo(path string, n *node) fs.FileInfo {
+	return &fileInfo{name: filepath.Base(path), node: n}
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return int64(len(fi.node.data)) }
+func (fi *fileInfo) Mode() fs.FileMode  { return fi.node.mode }
+func (fi *fileInfo) ModTime() time.Time { return fi.node.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.node.mode.IsDir() }
+func (fi *fileInfo) Sys() interface{}   { return nil }
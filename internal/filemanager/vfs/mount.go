@@ -0,0 +1,239 @@
+package vfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// ErrReadOnly is returned when modifying the inside of an archive
+var ErrReadOnly = errors.New("read-only file system")
+
+type mountedArchive struct {
+	fs      types.FileSystem
+	modTime time.Time
+	size    int64
+}
+
+// Mount layers archives over a base file system. Archive files found in a
+// path are browsed as read-only directories: stating or listing the archive
+// itself shows its contents, while every other operation on it acts on the
+// archive file. Archives are loaded on first access and reloaded when the
+// file changes.
+type Mount struct {
+	base     types.FileSystem
+	mu       sync.Mutex
+	archives map[string]*mountedArchive
+}
+
+func NewMount(base types.FileSystem) types.FileSystem {
+	return &Mount{
+		base:     base,
+		archives: make(map[string]*mountedArchive),
+	}
+}
+
+func (m *Mount) ReadDir(path string) ([]fs.DirEntry, error) {
+	fsys, _, err := m.route(path, true)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.ReadDir(path)
+}
+
+func (m *Mount) Stat(path string) (fs.FileInfo, error) {
+	fsys, _, err := m.route(path, true)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Stat(path)
+}
+
+func (m *Mount) Lstat(path string) (fs.FileInfo, error) {
+	fsys, _, err := m.route(path, false)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Lstat(path)
+}
+
+func (m *Mount) Open(path string) (io.ReadCloser, error) {
+	fsys, _, err := m.route(path, false)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Open(path)
+}
+
+func (m *Mount) Create(path string, perm fs.FileMode) (io.WriteCloser, error) {
+	fsys, err := m.writable("create", path)
+	if err != nil {
+		return nil, err
+	}
+	return fsys.Create(path, perm)
+}
+
+func (m *Mount) Rename(oldpath, newpath string) error {
+	if _, err := m.writable("rename", oldpath); err != nil {
+		return err
+	}
+	fsys, err := m.writable("rename", newpath)
+	if err != nil {
+		return err
+	}
+	return fsys.Rename(oldpath, newpath)
+}
+
+func (m *Mount) Remove(path string) error {
+	fsys, err := m.writable("remove", path)
+	if err != nil {
+		return err
+	}
+	return fsys.Remove(path)
+}
+
+func (m *Mount) RemoveAll(path string) error {
+	fsys, err := m.writable("remove", path)
+	if err != nil {
+		return err
+	}
+	return fsys.RemoveAll(path)
+}
+
+func (m *Mount) Mkdir(path string, perm fs.FileMode) error {
+	fsys, err := m.writable("mkdir", path)
+	if err != nil {
+		return err
+	}
+	return fsys.Mkdir(path, perm)
+}
+
+func (m *Mount) MkdirAll(path string, perm fs.FileMode) error {
+	fsys, err := m.writable("mkdir", path)
+	if err != nil {
+		return err
+	}
+	return fsys.MkdirAll(path, perm)
+}
+
+// Copy copies between the base and archives, which is how entries are
+// extracted
+func (m *Mount) Copy(src, dst string) error {
+	from, _, err := m.route(src, false)
+	if err != nil {
+		return err
+	}
+	to, err := m.writable("copy", dst)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return to.Copy(src, dst)
+	}
+	return copyBetween(from, to, src, dst)
+}
+
+func (m *Mount) Symlink(oldname, newname string) error {
+	fsys, err := m.writable("symlink", newname)
+	if err != nil {
+		return err
+	}
+	return fsys.Symlink(oldname, newname)
+}
+
+func (m *Mount) Readlink(path string) (string, error) {
+	fsys, _, err := m.route(path, false)
+	if err != nil {
+		return "", err
+	}
+	return fsys.Readlink(path)
+}
+
+func (m *Mount) Chtimes(path string, atime, mtime time.Time) error {
+	fsys, err := m.writable("chtimes", path)
+	if err != nil {
+		return err
+	}
+	return fsys.Chtimes(path, atime, mtime)
+}
+
+func (m *Mount) Chmod(path string, mode fs.FileMode) error {
+	fsys, err := m.writable("chmod", path)
+	if err != nil {
+		return err
+	}
+	return fsys.Chmod(path, mode)
+}
+
+func (m *Mount) ReadOnly(path string) bool {
+	_, readOnly, err := m.route(path, true)
+	return err == nil && readOnly
+}
+
+func (m *Mount) writable(op, path string) (types.FileSystem, error) {
+	fsys, readOnly, err := m.route(path, false)
+	if err != nil {
+		return nil, err
+	}
+	if readOnly {
+		return nil, &fs.PathError{Op: op, Path: path, Err: ErrReadOnly}
+	}
+	return fsys, nil
+}
+
+// route returns the file system holding path and whether it is read-only.
+// A path naming an archive file itself is routed into the archive only when
+// enter is set.
+func (m *Mount) route(path string, enter bool) (types.FileSystem, bool, error) {
+	path = filepath.Clean(path)
+	parts := strings.Split(path, string(filepath.Separator))
+
+	for i := 1; i <= len(parts); i++ {
+		if !IsArchive(parts[i-1]) {
+			continue
+		}
+		if i == len(parts) && !enter {
+			break
+		}
+
+		prefix := strings.Join(parts[:i], string(filepath.Separator))
+		if prefix == "" {
+			continue
+		}
+		info, err := m.base.Lstat(prefix)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		fsys, err := m.archive(prefix, info)
+		if err != nil {
+			return nil, false, err
+		}
+		return fsys, true, nil
+	}
+
+	return m.base, m.base.ReadOnly(path), nil
+}
+
+func (m *Mount) archive(path string, info fs.FileInfo) (types.FileSystem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if mounted, ok := m.archives[path]; ok &&
+		mounted.modTime.Equal(info.ModTime()) && mounted.size == info.Size() {
+		return mounted.fs, nil
+	}
+
+	fsys, err := loadArchive(m.base, path)
+	if err != nil {
+		return nil, err
+	}
+	m.archives[path] = &mountedArchive{fs: fsys, modTime: info.ModTime(), size: info.Size()}
+	return fsys, nil
+}
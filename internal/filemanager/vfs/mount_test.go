@@ -0,0 +1,150 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type MountTestSuite struct {
+	suite.Suite
+	base  types.FileSystem
+	mount types.FileSystem
+}
+
+func (s *MountTestSuite) SetupTest() {
+	s.base = NewMemory()
+	s.mount = NewMount(s.base)
+	s.Require().NoError(s.base.MkdirAll("/work/out", 0755))
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, content := range map[string]string{
+		"docs/readme.txt": "hello",
+		"main.go":         "package main",
+		"../escape.txt":   "nope",
+	} {
+		w, err := zw.Create(name)
+		s.Require().NoError(err)
+		_, err = w.Write([]byte(content))
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(zw.Close())
+	s.writeFile("/work/src.zip", zipped.Bytes())
+
+	var tarred bytes.Buffer
+	gz := gzip.NewWriter(&tarred)
+	tw := tar.NewWriter(gz)
+	s.Require().NoError(tw.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}))
+	s.Require().NoError(tw.WriteHeader(&tar.Header{Name: "dir/a.txt", Typeflag: tar.TypeReg, Mode: 0600, Size: 3}))
+	_, err := tw.Write([]byte("abc"))
+	s.Require().NoError(err)
+	s.Require().NoError(tw.WriteHeader(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "dir/a.txt"}))
+	s.Require().NoError(tw.Close())
+	s.Require().NoError(gz.Close())
+	s.writeFile("/work/src.tar.gz", tarred.Bytes())
+}
+
+func (s *MountTestSuite) writeFile(path string, content []byte) {
+	f, err := s.base.Create(path, 0644)
+	s.Require().NoError(err)
+	_, err = f.Write(content)
+	s.Require().NoError(err)
+	s.Require().NoError(f.Close())
+}
+
+func (s *MountTestSuite) readFile(fsys types.FileSystem, path string) string {
+	f, err := fsys.Open(path)
+	s.Require().NoError(err)
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	s.Require().NoError(err)
+	return string(content)
+}
+
+func (s *MountTestSuite) names(path string) []string {
+	entries, err := s.mount.ReadDir(path)
+	s.Require().NoError(err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func (s *MountTestSuite) TestBrowse() {
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "zip root", path: "/work/src.zip", want: []string{"docs", "main.go"}},
+		{name: "zip subdirectory", path: "/work/src.zip/docs", want: []string{"readme.txt"}},
+		{name: "tar.gz root", path: "/work/src.tar.gz", want: []string{"dir", "link"}},
+		{name: "base directory", path: "/work", want: []string{"out", "src.tar.gz", "src.zip"}},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			s.Equal(tt.want, s.names(tt.path))
+		})
+	}
+}
+
+func (s *MountTestSuite) TestArchiveIsFileAndDirectory() {
+	info, err := s.mount.Stat("/work/src.zip")
+	s.Require().NoError(err)
+	s.True(info.IsDir())
+
+	info, err = s.mount.Lstat("/work/src.zip")
+	s.Require().NoError(err)
+	s.True(info.Mode().IsRegular())
+
+	s.Equal("abc", s.readFile(s.mount, "/work/src.tar.gz/link"))
+}
+
+func (s *MountTestSuite) TestReadOnly() {
+	s.True(s.mount.ReadOnly("/work/src.zip"))
+	s.True(s.mount.ReadOnly("/work/src.zip/docs"))
+	s.False(s.mount.ReadOnly("/work"))
+
+	s.ErrorIs(s.mount.Remove("/work/src.zip/main.go"), ErrReadOnly)
+	s.ErrorIs(s.mount.Rename("/work/src.zip/main.go", "/work/out/main.go"), ErrReadOnly)
+	s.ErrorIs(s.mount.Mkdir("/work/src.zip/new", 0755), ErrReadOnly)
+
+	// The archive file itself stays an ordinary file
+	s.NoError(s.mount.Rename("/work/src.zip", "/work/out/src.zip"))
+}
+
+func (s *MountTestSuite) TestExtract() {
+	s.Require().NoError(s.mount.Copy("/work/src.zip/docs", "/work/out/docs"))
+	s.Equal("hello", s.readFile(s.base, "/work/out/docs/readme.txt"))
+
+	s.Require().NoError(s.mount.Copy("/work/src.tar.gz/dir", "/work/out/dir"))
+	info, err := s.base.Stat("/work/out/dir/a.txt")
+	s.Require().NoError(err)
+	s.Equal("-rw-------", info.Mode().String())
+}
+
+func (s *MountTestSuite) TestReloadsChangedArchive() {
+	s.Equal([]string{"docs", "main.go"}, s.names("/work/src.zip"))
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	_, err := zw.Create("other.txt")
+	s.Require().NoError(err)
+	s.Require().NoError(zw.Close())
+	s.writeFile("/work/src.zip", zipped.Bytes())
+
+	s.Equal([]string{"other.txt"}, s.names("/work/src.zip"))
+}
+
+func TestMountSuite(t *testing.T) {
+	suite.Run(t, new(MountTestSuite))
+}
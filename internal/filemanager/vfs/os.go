@@ -0,0 +1,81 @@
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// OS is the local disk
+type OS struct{}
+
+func NewOS() types.FileSystem {
+	return &OS{}
+}
+
+func (o *OS) ReadDir(path string) ([]fs.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
+func (o *OS) Stat(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+func (o *OS) Lstat(path string) (fs.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+func (o *OS) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+func (o *OS) Create(path string, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
+
+func (o *OS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (o *OS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (o *OS) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (o *OS) Mkdir(path string, perm fs.FileMode) error {
+	return os.Mkdir(path, perm)
+}
+
+func (o *OS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (o *OS) Copy(src, dst string) error {
+	return copyTree(o, src, dst)
+}
+
+func (o *OS) Symlink(oldname, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+func (o *OS) Readlink(path string) (string, error) {
+	return os.Readlink(path)
+}
+
+func (o *OS) Chtimes(path string, atime, mtime time.Time) error {
+	return os.Chtimes(path, atime, mtime)
+}
+
+func (o *OS) Chmod(path string, mode fs.FileMode) error {
+	return os.Chmod(path, mode)
+}
+
+func (o *OS) ReadOnly(path string) bool {
+	return false
+}
@@ -0,0 +1,229 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gunererd/grease/internal/filemanager/types"
+)
+
+// archiveWriter adds entries to an archive being written
+type archiveWriter interface {
+	add(name string, info fs.FileInfo, link string, content io.Reader) error
+	Close() error
+}
+
+// WriteArchive packs sources into a new archive at dst, each below its base
+// name, in the format the extension of dst names. Directories are added
+// recursively and symlinks are stored as links. A partially written archive
+// is removed again.
+func WriteArchive(ctx context.Context, fsys types.FileSystem, dst string, sources []string, progress CopyProgress) error {
+	if _, err := fsys.Lstat(dst); err == nil {
+		return &fs.PathError{Op: "pack", Path: dst, Err: fs.ErrExist}
+	}
+	format := ArchiveFormat(dst)
+	if format == "" {
+		return fmt.Errorf("unsupported archive format: %s", filepath.Base(dst))
+	}
+
+	out, err := fsys.Create(dst, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = writeArchive(ctx, fsys, out, format, sources, progress)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fsys.Remove(dst)
+	}
+	return err
+}
+
+func writeArchive(ctx context.Context, fsys types.FileSystem, out io.Writer, format string, sources []string, progress CopyProgress) error {
+	var w archiveWriter
+	switch format {
+	case "zip":
+		w = &zipWriter{zip.NewWriter(out)}
+	case "tar":
+		w = &tarWriter{tw: tar.NewWriter(out)}
+	case "tar.gz":
+		gz := gzip.NewWriter(out)
+		w = &tarWriter{tw: tar.NewWriter(gz), gz: gz}
+	}
+
+	for _, source := range sources {
+		if err := addTree(ctx, fsys, w, source, filepath.Base(source), progress); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func addTree(ctx context.Context, fsys types.FileSystem, w archiveWriter, src, name string, progress CopyProgress) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	info, err := fsys.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := fsys.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return w.add(name, info, link, nil)
+
+	case info.IsDir():
+		if err := w.add(name+"/", info, "", nil); err != nil {
+			return err
+		}
+		entries, err := fsys.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := addTree(ctx, fsys, w, filepath.Join(src, entry.Name()), path.Join(name, entry.Name()), progress); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case info.Mode().IsRegular():
+		in, err := fsys.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		content := &progressReader{ctx: ctx, r: in, path: src, progress: progress}
+		if err := w.add(name, info, "", content); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(src, 0, true)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("cannot pack %s: unsupported file type %s", src, info.Mode().Type())
+	}
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (z *zipWriter) add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if info.Mode().IsRegular() {
+		header.Method = zip.Deflate
+	}
+
+	w, err := z.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	// Links are stored with their target as content, the way zip(1) does
+	if link != "" {
+		content = strings.NewReader(link)
+	}
+	if content != nil {
+		_, err = io.Copy(w, content)
+	}
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.zw.Close()
+}
+
+type tarWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer // nil for an uncompressed tar
+}
+
+func (t *tarWriter) add(name string, info fs.FileInfo, link string, content io.Reader) error {
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	// Owner names depend on the machine the archive is written on
+	header.Uname, header.Gname = "", ""
+
+	if err := t.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if content != nil {
+		_, err = io.Copy(t.tw, content)
+	}
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	err := t.tw.Close()
+	if t.gz != nil {
+		if gzErr := t.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	return err
+}
+
+// progressReader reports reads and gives up once its context is done
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	path     string
+	progress CopyProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	if p.progress != nil && n > 0 {
+		p.progress(p.path, int64(n), false)
+	}
+	return n, err
+}
+
+// ExtractArchive unpacks the archive at src into the directory dst, which
+// must not exist yet. Members are written as they are read. Those that
+// would end up outside of dst are skipped, as when browsing the archive:
+// names with "..", symlinks pointing out of dst and members below a
+// symlink. A partial extraction is removed again.
+func ExtractArchive(ctx context.Context, fsys types.FileSystem, src, dst string, progress CopyProgress) error {
+	if _, err := fsys.Lstat(dst); err == nil {
+		return &fs.PathError{Op: "extract", Path: dst, Err: fs.ErrExist}
+	}
+
+	if err := fsys.Mkdir(dst, 0755); err != nil {
+		return err
+	}
+	if err := newUnpacker(ctx, fsys, dst, progress).unpack(fsys, src); err != nil {
+		fsys.RemoveAll(dst)
+		return fmt.Errorf("failed to extract %s: %w", src, err)
+	}
+	return nil
+}
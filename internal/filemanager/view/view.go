@@ -7,14 +7,19 @@ import (
 
 type View struct {
 	editor eTypes.Editor
+	finder types.Finder
 }
 
-func New(editor eTypes.Editor) types.View {
+func New(editor eTypes.Editor, finder types.Finder) types.View {
 	return &View{
 		editor: editor,
+		finder: finder,
 	}
 }
 
 func (v *View) Render() string {
+	if v.finder.Active() {
+		return v.finder.View(v.editor.Width(), v.editor.Height())
+	}
 	return v.editor.View()
 }
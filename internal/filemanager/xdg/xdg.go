@@ -0,0 +1,42 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const appName = "grease"
+
+// DataHome returns $XDG_DATA_HOME, falling back to ~/.local/share
+func DataHome() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".local", "share")
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME, falling back to ~/.config
+func ConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(homeDir(), ".config")
+}
+
+// DataFile returns the path of a grease data file under DataHome
+func DataFile(name string) string {
+	return filepath.Join(DataHome(), appName, name)
+}
+
+// ConfigFile returns the path of a grease config file under ConfigHome
+func ConfigFile(name string) string {
+	return filepath.Join(ConfigHome(), appName, name)
+}
+
+func homeDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return home
+}
@@ -0,0 +1,15 @@
+# greasecd starts grease and changes to the directory it was left in.
+# Load it from ~/.bashrc with:
+#   eval "$(grease --print-shell-init bash)"
+greasecd() {
+    local tmp dir ret
+    tmp="$(mktemp)" || return
+    command grease --choosedir "$tmp" "$@"
+    ret=$?
+    dir="$(cat -- "$tmp")"
+    rm -f -- "$tmp"
+    if [ -n "$dir" ] && [ -d "$dir" ] && [ "$dir" != "$PWD" ]; then
+        cd -- "$dir" || return
+    fi
+    return $ret
+}
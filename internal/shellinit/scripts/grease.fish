@@ -0,0 +1,14 @@
+# greasecd starts grease and changes to the directory it was left in.
+# Load it from ~/.config/fish/config.fish with:
+#   grease --print-shell-init fish | source
+function greasecd --description 'Start grease and cd to the directory it was left in'
+    set -l tmp (mktemp); or return
+    command grease --choosedir $tmp $argv
+    set -l ret $status
+    set -l dir (cat -- $tmp)
+    rm -f -- $tmp
+    if test -n "$dir" -a -d "$dir" -a "$dir" != "$PWD"
+        cd -- $dir; or return
+    end
+    return $ret
+end
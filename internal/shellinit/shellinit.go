@@ -0,0 +1,36 @@
+// Package shellinit holds the shell functions that change the shell's
+// directory to the one grease was left in
+package shellinit
+
+import (
+	"embed"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed scripts
+var scripts embed.FS
+
+// Script returns the function for shell, which is a name such as "bash" or
+// a path to it such as the value of $SHELL
+func Script(shell string) (string, error) {
+	name := filepath.Base(shell)
+	script, err := scripts.ReadFile("scripts/grease." + name)
+	if err != nil {
+		return "", fmt.Errorf("unsupported shell %q, expected %s", shell, strings.Join(Shells(), ", "))
+	}
+	return string(script), nil
+}
+
+// Shells lists the shells there is a function for
+func Shells() []string {
+	entries, _ := scripts.ReadDir("scripts")
+	var shells []string
+	for _, entry := range entries {
+		shells = append(shells, strings.TrimPrefix(filepath.Ext(entry.Name()), "."))
+	}
+	sort.Strings(shells)
+	return shells
+}
@@ -1,15 +1,83 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/gunererd/grease/internal/editor"
 	"github.com/gunererd/grease/internal/filemanager"
+	"github.com/gunererd/grease/internal/filemanager/types"
+	"github.com/gunererd/grease/internal/shellinit"
+	"github.com/muesli/termenv"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "apply" {
+		os.Exit(runApply(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	onFailure := flag.String("on-failure", "stop", "what to do after an operation fails on save: stop, skip or ask")
+	onConflict := flag.String("on-conflict", "ask", "what to do when a target already exists: ask, overwrite, skip or suffix")
+	pick := flag.Bool("pick", false, "choose entries for another program and print their absolute paths on exit")
+	pickOutput := flag.String("pick-output", "", "with --pick, write the picked paths to this file instead of stdout")
+	pickOnly := flag.String("pick-only", "any", "with --pick, what can be picked: any, files or dirs")
+	pickRoot := flag.String("pick-root", "", "with --pick, do not navigate above this directory")
+	choosedir := flag.String("choosedir", "", "write the last browsed directory to this file on exit")
+	printShellInit := flag.Bool("print-shell-init", false, "print a greasecd shell function that changes to the last browsed directory, for the shell given as argument or $SHELL")
+	var ignored []string
+	flag.Func("ignore", "glob pattern the finder and :grep skip besides the .gitignore files, such as node_modules/; may be repeated", func(pattern string) error {
+		ignored = append(ignored, pattern)
+		return nil
+	})
+	flag.Parse()
+
+	if *printShellInit {
+		shell := os.Getenv("SHELL")
+		if flag.NArg() > 0 {
+			shell = flag.Arg(0)
+		}
+		script, err := shellinit.Script(shell)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		fmt.Print(script)
+		return
+	}
+
+	policy, err := types.ParseFailurePolicy(*onFailure)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	resolution, err := types.ParseConflictResolution(*onConflict)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	only, err := types.ParsePickKind(*pickOnly)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// When picking, stdout is usually captured by the calling program, so
+	// the UI goes to the terminal itself and is coloured for it
+	var tty *os.File
+	if *pick {
+		if tty, err = os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer tty.Close()
+			lipgloss.SetColorProfile(termenv.NewOutput(tty).EnvColorProfile())
+		}
+	}
 
 	e, err := editor.Initialize(editor.WithLog("debug.log"))
 	if err != nil {
@@ -17,7 +85,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	fm, err := filemanager.Initialize(e, filemanager.WithLog("debug.log"))
+	fmOpts := []filemanager.Option{
+		filemanager.WithLog("debug.log"),
+		filemanager.WithFailurePolicy(policy),
+		filemanager.WithConflictResolution(resolution),
+		filemanager.WithIgnore(ignored...),
+	}
+	if *pick {
+		fmOpts = append(fmOpts, filemanager.WithPicker(*pickRoot, only))
+	}
+
+	fm, err := filemanager.Initialize(e, fmOpts...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing filemanager: %v\n", err)
 		os.Exit(1)
@@ -25,8 +103,10 @@ func main() {
 
 	// Get initial path (current directory if not specified)
 	initialPath := "."
-	if len(os.Args) > 1 {
-		initialPath = os.Args[1]
+	if flag.NArg() > 0 {
+		initialPath = flag.Arg(0)
+	} else if *pick && *pickRoot != "" {
+		initialPath = *pickRoot
 	}
 
 	// Load initial directory
@@ -35,14 +115,64 @@ func main() {
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(fm,
+	progOpts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 		tea.WithMouseAllMotion(),
-	)
+	}
+	if tty != nil {
+		progOpts = append(progOpts, tea.WithInputTTY(), tea.WithOutput(tty))
+	}
+
+	p := tea.NewProgram(fm, progOpts...)
 
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	if *choosedir != "" {
+		if err := writeChosenDir(fm, *choosedir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing directory: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *pick {
+		os.Exit(writePicked(fm.Picker(), *pickOutput))
+	}
+}
+
+// writeChosenDir writes the directory grease was left in to file for the
+// shell to change to. Inside an archive that is the directory holding it,
+// as a shell cannot enter the archive.
+func writeChosenDir(fm types.FileManager, file string) error {
+	dir := fm.DirectoryManager().CurrentPath()
+	for fm.FileSystem().ReadOnly(dir) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+	return os.WriteFile(file, []byte(dir+"\n"), 0644)
+}
+
+// writePicked prints the picked paths one per line, to file when it is not
+// empty. Nothing is written when picking was cancelled, which exits with 1.
+func writePicked(picker types.Picker, file string) int {
+	paths, ok := picker.Picked()
+	if !ok {
+		return 1
+	}
+
+	var out strings.Builder
+	for _, path := range paths {
+		out.WriteString(path + "\n")
+	}
+
+	if file == "" {
+		fmt.Print(out.String())
+		return 0
+	}
+	if err := os.WriteFile(file, []byte(out.String()), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing picked paths: %v\n", err)
+		return 1
+	}
+	return 0
 }